@@ -117,3 +117,63 @@ func (a Actions) Forget() bool {
 
 	return a[0] == ActionForget
 }
+
+// ActionReason is a hint recorded by Terraform about why a particular
+// set of Actions was chosen for a resource change.
+type ActionReason string
+
+const (
+	// ActionReasonReplaceBecauseTainted denotes a replacement caused by
+	// the prior object being marked as tainted.
+	ActionReasonReplaceBecauseTainted ActionReason = "replace_because_tainted"
+
+	// ActionReasonReplaceBecauseCannotUpdate denotes a replacement
+	// caused by changes to attributes that cannot be updated in-place.
+	ActionReasonReplaceBecauseCannotUpdate ActionReason = "replace_because_cannot_update"
+
+	// ActionReasonReplaceByRequest denotes a replacement requested
+	// using the -replace planning option.
+	ActionReasonReplaceByRequest ActionReason = "replace_by_request"
+
+	// ActionReasonReplaceByTriggers denotes a replacement caused by the
+	// replace_triggered_by lifecycle argument.
+	ActionReasonReplaceByTriggers ActionReason = "replace_by_triggers"
+
+	// ActionReasonDeleteBecauseNoResourceConfig denotes a deletion
+	// caused by the resource block being removed from configuration.
+	ActionReasonDeleteBecauseNoResourceConfig ActionReason = "delete_because_no_resource_config"
+
+	// ActionReasonDeleteBecauseWrongRepetition denotes a deletion
+	// caused by switching between count, for_each and a single
+	// instance.
+	ActionReasonDeleteBecauseWrongRepetition ActionReason = "delete_because_wrong_repetition"
+
+	// ActionReasonDeleteBecauseCountIndex denotes a deletion caused by
+	// the count argument decreasing.
+	ActionReasonDeleteBecauseCountIndex ActionReason = "delete_because_count_index"
+
+	// ActionReasonDeleteBecauseEachKey denotes a deletion caused by a
+	// key being removed from the for_each argument.
+	ActionReasonDeleteBecauseEachKey ActionReason = "delete_because_each_key"
+
+	// ActionReasonDeleteBecauseNoModule denotes a deletion caused by
+	// the containing module instance no longer being declared.
+	ActionReasonDeleteBecauseNoModule ActionReason = "delete_because_no_module"
+
+	// ActionReasonDeleteBecauseNoMoveTarget denotes a deletion caused
+	// by a moved block whose target is not declared.
+	ActionReasonDeleteBecauseNoMoveTarget ActionReason = "delete_because_no_move_target"
+
+	// ActionReasonReadBecauseConfigUnknown denotes a data source read
+	// deferred to apply because its configuration is not yet known.
+	ActionReasonReadBecauseConfigUnknown ActionReason = "read_because_config_unknown"
+
+	// ActionReasonReadBecauseDependencyPending denotes a data source
+	// read deferred to apply because one of its dependencies has
+	// pending changes.
+	ActionReasonReadBecauseDependencyPending ActionReason = "read_because_dependency_pending"
+
+	// ActionReasonReadBecauseCheckNested denotes a data source read
+	// deferred to apply because it is nested within a check block.
+	ActionReasonReadBecauseCheckNested ActionReason = "read_because_check_nested"
+)
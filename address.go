@@ -0,0 +1,710 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// InstanceKey is the key of a single instance of a resource or module
+// that was expanded using "count" or "for_each". It is either an
+// IntKey or a StringKey. A nil InstanceKey (NoKey) denotes an object
+// that was not expanded.
+type InstanceKey interface {
+	instanceKey()
+
+	// String returns the key in the bracketed form used by Terraform
+	// addresses, ie: `[0]` or `["foo"]`.
+	String() string
+
+	// Value returns the key as either an int or a string, matching the
+	// representation used in fields such as ResourceChange.Index.
+	Value() interface{}
+}
+
+// NoKey is the InstanceKey of an object that was not created using
+// "count" or "for_each".
+var NoKey InstanceKey
+
+// IntKey is the InstanceKey of an object created using "count".
+type IntKey int
+
+func (IntKey) instanceKey() {}
+
+func (k IntKey) String() string {
+	return "[" + strconv.Itoa(int(k)) + "]"
+}
+
+func (k IntKey) Value() interface{} {
+	return int(k)
+}
+
+// StringKey is the InstanceKey of an object created using "for_each".
+type StringKey string
+
+func (StringKey) instanceKey() {}
+
+func (k StringKey) String() string {
+	return "[" + quoteAddressString(string(k)) + "]"
+}
+
+func (k StringKey) Value() interface{} {
+	return string(k)
+}
+
+// InstanceKeyFromValue converts a decoded instance key, such as the
+// value of ResourceChange.Index or CheckDynamicAddress.InstanceKey, to
+// an InstanceKey. A nil value returns NoKey.
+func InstanceKeyFromValue(v interface{}) (InstanceKey, error) {
+	switch v := v.(type) {
+	case nil:
+		return NoKey, nil
+	case string:
+		return StringKey(v), nil
+	case int:
+		return IntKey(v), nil
+	case int64:
+		return IntKey(v), nil
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("instance key %v is not an integer", v)
+		}
+		return IntKey(v), nil
+	case json.Number:
+		i, err := strconv.Atoi(v.String())
+		if err != nil {
+			return nil, fmt.Errorf("instance key %q is not an integer", v)
+		}
+		return IntKey(i), nil
+	case *big.Float:
+		i, ok := bigFloatInt(v)
+		if !ok {
+			return nil, fmt.Errorf("instance key %s is not an integer", v.Text('f', -1))
+		}
+		return IntKey(i), nil
+	}
+
+	return nil, fmt.Errorf("unsupported instance key type %T", v)
+}
+
+// instanceKeyEqual returns true if both keys are of the same kind and
+// value.
+func instanceKeyEqual(a, b InstanceKey) bool {
+	return a == b
+}
+
+// instanceKeyLess orders instance keys with NoKey first, then integer
+// keys in numeric order and finally string keys in lexical order.
+func instanceKeyLess(a, b InstanceKey) bool {
+	rank := func(k InstanceKey) int {
+		switch k.(type) {
+		case IntKey:
+			return 1
+		case StringKey:
+			return 2
+		}
+		return 0
+	}
+
+	ra, rb := rank(a), rank(b)
+	if ra != rb {
+		return ra < rb
+	}
+
+	switch a := a.(type) {
+	case IntKey:
+		return a < b.(IntKey)
+	case StringKey:
+		return a < b.(StringKey)
+	}
+	return false
+}
+
+// keyString returns the bracketed representation of k, or an empty
+// string for NoKey.
+func keyString(k InstanceKey) string {
+	if k == NoKey {
+		return ""
+	}
+	return k.String()
+}
+
+// ModulePath is the static address of a module in configuration, as
+// a list of module call names starting from the root module. The root
+// module is represented by an empty path.
+type ModulePath []string
+
+// IsRoot returns true if the path refers to the root module.
+func (p ModulePath) IsRoot() bool {
+	return len(p) == 0
+}
+
+// String returns the path in the form "module.a.module.b". The root
+// module is represented by an empty string.
+func (p ModulePath) String() string {
+	var b strings.Builder
+	for i, name := range p {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString("module.")
+		b.WriteString(name)
+	}
+	return b.String()
+}
+
+// Equal returns true if both paths refer to the same module.
+func (p ModulePath) Equal(other ModulePath) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ModuleInstanceStep is a single step in a ModuleInstancePath, ie:
+// `module.foo["bar"]`.
+type ModuleInstanceStep struct {
+	// The name of the module call.
+	Name string
+
+	// The instance key of the module, or NoKey if the module call does
+	// not use "count" or "for_each".
+	Key InstanceKey
+}
+
+// String returns the step in the form `module.name[key]`.
+func (s ModuleInstanceStep) String() string {
+	return "module." + s.Name + keyString(s.Key)
+}
+
+// ModuleInstancePath is the absolute address of a module instance, as
+// a list of steps starting from the root module. The root module is
+// represented by an empty path.
+type ModuleInstancePath []ModuleInstanceStep
+
+// ParseModuleInstancePath parses a module instance address such as
+// `module.a["x"].module.b[0]`. An empty string parses as the root
+// module.
+func ParseModuleInstancePath(s string) (ModuleInstancePath, error) {
+	p := &addressParser{src: s}
+	path, err := p.parseModuleSteps(false)
+	if err != nil {
+		return nil, err
+	}
+	if !p.eof() {
+		return nil, p.errorf("unexpected %q", p.rest())
+	}
+	return path, nil
+}
+
+// IsRoot returns true if the path refers to the root module.
+func (p ModuleInstancePath) IsRoot() bool {
+	return len(p) == 0
+}
+
+// String returns the path in the form `module.a["x"].module.b[0]`. The
+// root module is represented by an empty string.
+func (p ModuleInstancePath) String() string {
+	var b strings.Builder
+	for i, step := range p {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(step.String())
+	}
+	return b.String()
+}
+
+// Module returns the static module path of p, dropping all instance
+// keys.
+func (p ModuleInstancePath) Module() ModulePath {
+	if len(p) == 0 {
+		return nil
+	}
+	ret := make(ModulePath, len(p))
+	for i, step := range p {
+		ret[i] = step.Name
+	}
+	return ret
+}
+
+// Equal returns true if both paths refer to the same module instance.
+func (p ModuleInstancePath) Equal(other ModuleInstancePath) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i].Name != other[i].Name || !instanceKeyEqual(p[i].Key, other[i].Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Less returns true if p sorts before other. Paths are ordered step by
+// step, first by name and then by instance key, with a parent module
+// sorting before any of its children.
+func (p ModuleInstancePath) Less(other ModuleInstancePath) bool {
+	for i := 0; i < len(p) && i < len(other); i++ {
+		if p[i].Name != other[i].Name {
+			return p[i].Name < other[i].Name
+		}
+		if !instanceKeyEqual(p[i].Key, other[i].Key) {
+			return instanceKeyLess(p[i].Key, other[i].Key)
+		}
+	}
+	return len(p) < len(other)
+}
+
+// ResourceAddress is the parsed form of an absolute resource instance
+// address, such as those found in ResourceChange.Address and
+// StateResource.Address.
+type ResourceAddress struct {
+	// The module instance the resource belongs to. Empty for resources
+	// in the root module.
+	Module ModuleInstancePath
+
+	// The resource mode.
+	Mode ResourceMode
+
+	// The resource type, example: "aws_instance" for aws_instance.foo.
+	Type string
+
+	// The resource name, example: "foo" for aws_instance.foo.
+	Name string
+
+	// The instance key of the resource, or NoKey if the resource does
+	// not use "count" or "for_each".
+	Key InstanceKey
+
+	// The deposed key of the object, if the address refers to a deposed
+	// object rather than the current object of the instance.
+	DeposedKey string
+}
+
+// ParseResourceAddress parses an absolute resource instance address
+// such as `module.a["x"].data.aws_ami.web[0]`.
+//
+// A trailing ` (deposed object KEY)` suffix, as used by Terraform when
+// displaying deposed objects, is parsed into DeposedKey.
+func ParseResourceAddress(s string) (ResourceAddress, error) {
+	p := &addressParser{src: s}
+	addr, err := p.parseResource()
+	if err != nil {
+		return ResourceAddress{}, err
+	}
+	return addr, nil
+}
+
+// String returns the address in the canonical form used by Terraform.
+func (a ResourceAddress) String() string {
+	var b strings.Builder
+	if len(a.Module) > 0 {
+		b.WriteString(a.Module.String())
+		b.WriteByte('.')
+	}
+	if a.Mode == DataResourceMode {
+		b.WriteString("data.")
+	}
+	b.WriteString(a.Type)
+	b.WriteByte('.')
+	b.WriteString(a.Name)
+	b.WriteString(keyString(a.Key))
+	if a.DeposedKey != "" {
+		b.WriteString(" (deposed object ")
+		b.WriteString(a.DeposedKey)
+		b.WriteByte(')')
+	}
+	return b.String()
+}
+
+// Equal returns true if both addresses refer to the same object.
+func (a ResourceAddress) Equal(other ResourceAddress) bool {
+	return a.Module.Equal(other.Module) &&
+		a.mode() == other.mode() &&
+		a.Type == other.Type &&
+		a.Name == other.Name &&
+		instanceKeyEqual(a.Key, other.Key) &&
+		a.DeposedKey == other.DeposedKey
+}
+
+// Less returns true if a sorts before other. Addresses are ordered by
+// module instance, then mode (managed resources first), type, name,
+// instance key and finally deposed key.
+func (a ResourceAddress) Less(other ResourceAddress) bool {
+	switch {
+	case !a.Module.Equal(other.Module):
+		return a.Module.Less(other.Module)
+	case a.mode() != other.mode():
+		return a.mode() == ManagedResourceMode
+	case a.Type != other.Type:
+		return a.Type < other.Type
+	case a.Name != other.Name:
+		return a.Name < other.Name
+	case !instanceKeyEqual(a.Key, other.Key):
+		return instanceKeyLess(a.Key, other.Key)
+	}
+	return a.DeposedKey < other.DeposedKey
+}
+
+// mode returns the resource mode, treating an empty mode as managed.
+func (a ResourceAddress) mode() ResourceMode {
+	return resourceModeOrManaged(a.Mode)
+}
+
+// ConfigAddress returns the address of the resource in configuration,
+// dropping the module and resource instance keys and the deposed key.
+func (a ResourceAddress) ConfigAddress() ConfigResourceAddress {
+	return ConfigResourceAddress{
+		Module: a.Module.Module(),
+		Mode:   a.mode(),
+		Type:   a.Type,
+		Name:   a.Name,
+	}
+}
+
+// ConfigResourceAddress is the static address of a resource in
+// configuration, without any instance keys.
+type ConfigResourceAddress struct {
+	// The module the resource is declared in. Empty for resources in
+	// the root module.
+	Module ModulePath
+
+	// The resource mode.
+	Mode ResourceMode
+
+	// The resource type, example: "aws_instance" for aws_instance.foo.
+	Type string
+
+	// The resource name, example: "foo" for aws_instance.foo.
+	Name string
+}
+
+// String returns the absolute address in the form
+// "module.a.module.b.aws_instance.foo".
+func (a ConfigResourceAddress) String() string {
+	if len(a.Module) == 0 {
+		return a.RelativeString()
+	}
+	return a.Module.String() + "." + a.RelativeString()
+}
+
+// RelativeString returns the address relative to its module, ie:
+// "aws_instance.foo" or "data.aws_ami.foo". This matches the format of
+// ConfigResource.Address.
+func (a ConfigResourceAddress) RelativeString() string {
+	if a.Mode == DataResourceMode {
+		return "data." + a.Type + "." + a.Name
+	}
+	return a.Type + "." + a.Name
+}
+
+// Equal returns true if both addresses refer to the same resource.
+func (a ConfigResourceAddress) Equal(other ConfigResourceAddress) bool {
+	return a.Module.Equal(other.Module) && a.String() == other.String()
+}
+
+// ParseAddress parses the Address and DeposedKey of the resource
+// change into a ResourceAddress.
+func (rc *ResourceChange) ParseAddress() (ResourceAddress, error) {
+	addr, err := ParseResourceAddress(rc.Address)
+	if err != nil {
+		return ResourceAddress{}, err
+	}
+	if rc.DeposedKey != "" {
+		addr.DeposedKey = rc.DeposedKey
+	}
+	return addr, nil
+}
+
+// ParseAddress parses the Address and DeposedKey of the state resource
+// into a ResourceAddress.
+func (r *StateResource) ParseAddress() (ResourceAddress, error) {
+	addr, err := ParseResourceAddress(r.Address)
+	if err != nil {
+		return ResourceAddress{}, err
+	}
+	if r.DeposedKey != "" {
+		addr.DeposedKey = r.DeposedKey
+	}
+	return addr, nil
+}
+
+const deposedSuffixPrefix = " (deposed object "
+
+// addressParser is a small recursive descent parser for resource and
+// module instance addresses, also used for attribute paths.
+type addressParser struct {
+	src string
+	pos int
+
+	// what names the kind of input in errors, "address" if empty.
+	what string
+}
+
+func (p *addressParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *addressParser) rest() string {
+	return p.src[p.pos:]
+}
+
+func (p *addressParser) errorf(format string, args ...interface{}) error {
+	what := p.what
+	if what == "" {
+		what = "address"
+	}
+	return fmt.Errorf("invalid %s %q at offset %d: %s", what, p.src, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *addressParser) consume(s string) bool {
+	if strings.HasPrefix(p.rest(), s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+// parseModuleSteps parses any number of `module.name[key]` steps. When
+// dotAfter is true each step must be followed by a dot, as is the case
+// when a resource follows the module path.
+func (p *addressParser) parseModuleSteps(dotAfter bool) (ModuleInstancePath, error) {
+	var path ModuleInstancePath
+	for !p.eof() {
+		start := p.pos
+		if !p.consume("module.") {
+			if dotAfter {
+				return path, nil
+			}
+			return nil, p.errorf("expected \"module.\"")
+		}
+		name, err := p.parseIdent()
+		if err != nil {
+			return nil, err
+		}
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case dotAfter && !p.consume("."):
+			// This is not a module step after all; leave it for the
+			// resource parser, which will report a sensible error.
+			p.pos = start
+			return path, nil
+		case !dotAfter && !p.eof() && !p.consume("."):
+			return nil, p.errorf("expected \".\"")
+		case !dotAfter && p.eof() && strings.HasSuffix(p.src, "."):
+			return nil, p.errorf("unexpected trailing \".\"")
+		}
+		path = append(path, ModuleInstanceStep{Name: name, Key: key})
+	}
+	return path, nil
+}
+
+func (p *addressParser) parseResource() (ResourceAddress, error) {
+	var addr ResourceAddress
+	var err error
+
+	addr.Module, err = p.parseModuleSteps(true)
+	if err != nil {
+		return addr, err
+	}
+
+	addr.Mode = ManagedResourceMode
+	if p.consume("data.") {
+		addr.Mode = DataResourceMode
+	}
+
+	if addr.Type, err = p.parseIdent(); err != nil {
+		return addr, err
+	}
+	if addr.Type == "module" {
+		return addr, p.errorf("expected resource after module path")
+	}
+	if !p.consume(".") {
+		return addr, p.errorf("expected \".\" after resource type")
+	}
+	if addr.Name, err = p.parseIdent(); err != nil {
+		return addr, err
+	}
+	if addr.Key, err = p.parseKey(); err != nil {
+		return addr, err
+	}
+
+	if p.consume(deposedSuffixPrefix) {
+		end := strings.IndexByte(p.rest(), ')')
+		if end <= 0 {
+			return addr, p.errorf("unterminated deposed object suffix")
+		}
+		addr.DeposedKey = p.rest()[:end]
+		p.pos += end + 1
+	}
+
+	if !p.eof() {
+		return addr, p.errorf("unexpected %q", p.rest())
+	}
+	return addr, nil
+}
+
+// parseIdent parses an identifier as accepted by Terraform for resource
+// types, resource names and module call names.
+func (p *addressParser) parseIdent() (string, error) {
+	start := p.pos
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.rest())
+		if !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-') {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		return "", p.errorf("expected identifier")
+	}
+	return p.src[start:p.pos], nil
+}
+
+// parseKey parses an optional bracketed instance key.
+func (p *addressParser) parseKey() (InstanceKey, error) {
+	if !p.consume("[") {
+		return NoKey, nil
+	}
+
+	var key InstanceKey
+	if strings.HasPrefix(p.rest(), `"`) {
+		s, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		key = StringKey(s)
+	} else {
+		start := p.pos
+		for !p.eof() && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		i, err := strconv.Atoi(p.src[start:p.pos])
+		if err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid instance key")
+		}
+		key = IntKey(i)
+	}
+
+	if !p.consume("]") {
+		return nil, p.errorf("expected \"]\"")
+	}
+	return key, nil
+}
+
+// parseQuoted parses a double-quoted string using the HCL escaping
+// rules that Terraform uses when rendering string instance keys.
+func (p *addressParser) parseQuoted() (string, error) {
+	p.pos++ // opening quote
+
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			p.pos++
+			if p.eof() {
+				return "", p.errorf("unterminated escape sequence")
+			}
+			esc := p.src[p.pos]
+			p.pos++
+			switch esc {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case '"':
+				b.WriteByte('"')
+			case '\\':
+				b.WriteByte('\\')
+			case 'u', 'U':
+				n := 4
+				if esc == 'U' {
+					n = 8
+				}
+				if len(p.rest()) < n {
+					return "", p.errorf("invalid unicode escape")
+				}
+				cp, err := strconv.ParseUint(p.rest()[:n], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(cp))
+				p.pos += n
+			default:
+				return "", p.errorf("invalid escape sequence \\%c", esc)
+			}
+		case strings.HasPrefix(p.rest(), "$${"), strings.HasPrefix(p.rest(), "%%{"):
+			b.WriteByte(c)
+			b.WriteByte('{')
+			p.pos += 3
+		default:
+			b.WriteByte(c)
+			p.pos++
+		}
+	}
+}
+
+// quoteAddressString quotes s using the HCL escaping rules Terraform
+// uses for string instance keys.
+func quoteAddressString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '$', '%':
+			b.WriteRune(r)
+			if strings.HasPrefix(s[i+1:], "{") {
+				b.WriteRune(r)
+			}
+		default:
+			if !unicode.IsPrint(r) {
+				if r > 0xFFFF {
+					fmt.Fprintf(&b, `\U%08x`, r)
+				} else {
+					fmt.Fprintf(&b, `\u%04x`, r)
+				}
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
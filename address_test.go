@@ -0,0 +1,265 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseResourceAddress(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected ResourceAddress
+		config   string
+	}{
+		{
+			in: "aws_instance.web",
+			expected: ResourceAddress{
+				Mode: ManagedResourceMode,
+				Type: "aws_instance",
+				Name: "web",
+			},
+			config: "aws_instance.web",
+		},
+		{
+			in: "data.aws_ami.ubuntu[0]",
+			expected: ResourceAddress{
+				Mode: DataResourceMode,
+				Type: "aws_ami",
+				Name: "ubuntu",
+				Key:  IntKey(0),
+			},
+			config: "data.aws_ami.ubuntu",
+		},
+		{
+			in: `module.a["x"].module.b[0].aws_instance.web["k"]`,
+			expected: ResourceAddress{
+				Module: ModuleInstancePath{
+					{Name: "a", Key: StringKey("x")},
+					{Name: "b", Key: IntKey(0)},
+				},
+				Mode: ManagedResourceMode,
+				Type: "aws_instance",
+				Name: "web",
+				Key:  StringKey("k"),
+			},
+			config: "module.a.module.b.aws_instance.web",
+		},
+		{
+			in: `module.files.local_file.foo["a \"quoted\"\\path\n$${x}%%{y}"]`,
+			expected: ResourceAddress{
+				Module: ModuleInstancePath{{Name: "files"}},
+				Mode:   ManagedResourceMode,
+				Type:   "local_file",
+				Name:   "foo",
+				Key:    StringKey("a \"quoted\"\\path\n${x}%{y}"),
+			},
+			config: "module.files.local_file.foo",
+		},
+		{
+			in: "null_resource.bar (deposed object 1a2b3c4d)",
+			expected: ResourceAddress{
+				Mode:       ManagedResourceMode,
+				Type:       "null_resource",
+				Name:       "bar",
+				DeposedKey: "1a2b3c4d",
+			},
+			config: "null_resource.bar",
+		},
+		{
+			in: "module.data.data.null_data_source.data",
+			expected: ResourceAddress{
+				Module: ModuleInstancePath{{Name: "data"}},
+				Mode:   DataResourceMode,
+				Type:   "null_data_source",
+				Name:   "data",
+			},
+			config: "module.data.data.null_data_source.data",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseResourceAddress(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Fatalf("unexpected address: %s", diff)
+			}
+
+			if actual.String() != tc.in {
+				t.Fatalf("expected round trip to %q, got %q", tc.in, actual.String())
+			}
+
+			if !actual.Equal(tc.expected) {
+				t.Fatal("expected parsed address to equal itself")
+			}
+
+			if got := actual.ConfigAddress().String(); got != tc.config {
+				t.Fatalf("expected config address %q, got %q", tc.config, got)
+			}
+		})
+	}
+}
+
+func TestParseResourceAddress_invalid(t *testing.T) {
+	cases := []string{
+		"",
+		"aws_instance",
+		"aws_instance.",
+		"module.foo",
+		"aws_instance.web[",
+		"aws_instance.web[x]",
+		`aws_instance.web["x]`,
+		`aws_instance.web["\q"]`,
+		"aws_instance.web extra",
+		"aws_instance.web (deposed object ",
+	}
+
+	for _, in := range cases {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseResourceAddress(in); err == nil {
+				t.Fatalf("expected error parsing %q", in)
+			}
+		})
+	}
+}
+
+func TestParseModuleInstancePath(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected ModuleInstancePath
+		err      bool
+	}{
+		{in: "", expected: nil},
+		{in: "module.foo", expected: ModuleInstancePath{{Name: "foo"}}},
+		{
+			in:       `module.foo[1].module.bar["baz"]`,
+			expected: ModuleInstancePath{{Name: "foo", Key: IntKey(1)}, {Name: "bar", Key: StringKey("baz")}},
+		},
+		{in: "module.foo.", err: true},
+		{in: "module.foo.aws_instance.bar", err: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseModuleInstancePath(tc.in)
+			if tc.err {
+				if err == nil {
+					t.Fatal("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Fatalf("unexpected path: %s", diff)
+			}
+			if actual.String() != tc.in {
+				t.Fatalf("expected round trip to %q, got %q", tc.in, actual.String())
+			}
+		})
+	}
+}
+
+func TestResourceAddressLess(t *testing.T) {
+	in := []string{
+		`module.b.aws_instance.a`,
+		`module.a["y"].aws_instance.a`,
+		`module.a[0].aws_instance.a`,
+		`data.aws_instance.a`,
+		`aws_instance.a["x"]`,
+		`aws_instance.a[10]`,
+		`aws_instance.a[2]`,
+		`aws_instance.a[2] (deposed object abc)`,
+		`aws_instance.a`,
+		`module.a.aws_instance.a`,
+	}
+	expected := []string{
+		`aws_instance.a`,
+		`aws_instance.a[2]`,
+		`aws_instance.a[2] (deposed object abc)`,
+		`aws_instance.a[10]`,
+		`aws_instance.a["x"]`,
+		`data.aws_instance.a`,
+		`module.a.aws_instance.a`,
+		`module.a[0].aws_instance.a`,
+		`module.a["y"].aws_instance.a`,
+		`module.b.aws_instance.a`,
+	}
+
+	addrs := make([]ResourceAddress, len(in))
+	for i, s := range in {
+		addr, err := ParseResourceAddress(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		addrs[i] = addr
+	}
+
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Less(addrs[j]) })
+
+	actual := make([]string, len(addrs))
+	for i, addr := range addrs {
+		actual[i] = addr.String()
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected order: %s", diff)
+	}
+}
+
+func TestInstanceKeyFromValue(t *testing.T) {
+	cases := []struct {
+		in       interface{}
+		expected InstanceKey
+		err      bool
+	}{
+		{in: nil, expected: NoKey},
+		{in: float64(2), expected: IntKey(2)},
+		{in: json.Number("3"), expected: IntKey(3)},
+		{in: "foo", expected: StringKey("foo")},
+		{in: 1.5, err: true},
+		{in: true, err: true},
+	}
+
+	for _, tc := range cases {
+		actual, err := InstanceKeyFromValue(tc.in)
+		if tc.err {
+			if err == nil {
+				t.Fatalf("expected error for %#v", tc.in)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tc.expected {
+			t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+		}
+	}
+}
+
+func TestResourceChangeParseAddress(t *testing.T) {
+	rc := &ResourceChange{
+		Address:    `module.files.local_file.foo["file1.txt"]`,
+		DeposedKey: "00000001",
+	}
+
+	addr, err := rc.ParseAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `module.files.local_file.foo["file1.txt"] (deposed object 00000001)`
+	if addr.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, addr.String())
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+// AnnotatedValue is a node of a value tree that merges a JSON value
+// with its unknown and sensitive masks, such as Change.After,
+// Change.AfterUnknown and Change.AfterSensitive, so that they do not
+// need to be walked in lockstep.
+//
+// A node is either an object or map, with Attributes set; a list, set
+// or tuple, with Elements set; or a primitive or null value, with
+// neither set.
+type AnnotatedValue struct {
+	// Value is the value of a primitive: a string, a bool, or a number
+	// as decoded from JSON. It is nil for objects, lists, null values
+	// and unknown values.
+	Value interface{}
+
+	// Attributes holds the attributes of an object, or the elements of
+	// a map. It is nil for any other kind of value.
+	Attributes map[string]*AnnotatedValue
+
+	// Elements holds the elements of a list, set or tuple. It is nil
+	// for any other kind of value.
+	Elements []*AnnotatedValue
+
+	// Known is false if the value will only be known after apply. The
+	// values nested within an unknown value are also unknown.
+	Known bool
+
+	// Sensitive is true if the value is sensitive. The values nested
+	// within a sensitive value are also sensitive.
+	Sensitive bool
+
+	// decoded is true for nodes built from JSON trees, which record how
+	// the value and masks were represented so that JSON can reproduce
+	// them exactly. Nodes built by hand use the representation of
+	// Terraform instead.
+	decoded      bool
+	valueAbsent  bool
+	unknownEnc   maskEncoding
+	sensitiveEnc maskEncoding
+}
+
+// maskForm is the way a node was represented in a mask tree.
+type maskForm int
+
+const (
+	maskCanonical maskForm = iota
+	maskAbsent
+	maskBool
+	maskContainer
+	maskRaw
+)
+
+// maskEncoding records the representation of a node in a mask tree.
+// raw holds the mask verbatim for maskRaw, used when the shape of the
+// mask does not match the shape of the value.
+type maskEncoding struct {
+	form maskForm
+	raw  interface{}
+}
+
+// NewAnnotatedValue builds an annotated value tree from a JSON value
+// and its unknown and sensitive masks, following the conventions of
+// Change.After, Change.AfterUnknown and Change.AfterSensitive. Either
+// mask may be nil.
+//
+// Values that are omitted from value because they are unknown, as
+// Terraform does for object attributes, are present in the tree.
+func NewAnnotatedValue(value, unknown, sensitive interface{}) *AnnotatedValue {
+	return newAnnotatedValue(value, true, unknown, unknown != nil, sensitive, sensitive != nil, false, false)
+}
+
+// BeforeAnnotated returns Before annotated with BeforeSensitive.
+func (c *Change) BeforeAnnotated() *AnnotatedValue {
+	return NewAnnotatedValue(c.Before, nil, c.BeforeSensitive)
+}
+
+// AfterAnnotated returns After annotated with AfterUnknown and
+// AfterSensitive.
+func (c *Change) AfterAnnotated() *AnnotatedValue {
+	return NewAnnotatedValue(c.After, c.AfterUnknown, c.AfterSensitive)
+}
+
+// AnnotatedValues returns AttributeValues annotated with
+// SensitiveValues.
+func (r *StateResource) AnnotatedValues() *AnnotatedValue {
+	var values interface{}
+	if r.AttributeValues != nil {
+		values = r.AttributeValues
+	}
+	return NewAnnotatedValue(values, nil, r.SensitiveValues)
+}
+
+func newAnnotatedValue(value interface{}, valuePresent bool, unknown interface{}, unknownPresent bool, sensitive interface{}, sensitivePresent bool, parentUnknown, parentSensitive bool) *AnnotatedValue {
+	v := &AnnotatedValue{
+		Known:        !parentUnknown && !maskAtPath(unknown, nil),
+		Sensitive:    parentSensitive || maskAtPath(sensitive, nil),
+		decoded:      true,
+		valueAbsent:  !valuePresent,
+		unknownEnc:   newMaskEncoding(value, unknown, unknownPresent),
+		sensitiveEnc: newMaskEncoding(value, sensitive, sensitivePresent),
+	}
+	if v.unknownEnc.form != maskContainer {
+		unknown = nil
+	}
+	if v.sensitiveEnc.form != maskContainer {
+		sensitive = nil
+	}
+
+	switch tv := value.(type) {
+	case map[string]interface{}:
+		um, _ := unknown.(map[string]interface{})
+		sm, _ := sensitive.(map[string]interface{})
+		v.Attributes = make(map[string]*AnnotatedValue, len(tv))
+		for _, key := range mergedKeys(tv, mergedMap(um, sm)) {
+			cv, cvOk := tv[key]
+			cu, cuOk := um[key]
+			cs, csOk := sm[key]
+			v.Attributes[key] = newAnnotatedValue(cv, cvOk, cu, cuOk, cs, csOk, !v.Known, v.Sensitive)
+		}
+	case []interface{}:
+		ul, _ := unknown.([]interface{})
+		sl, _ := sensitive.([]interface{})
+		n := len(tv)
+		if len(ul) > n {
+			n = len(ul)
+		}
+		if len(sl) > n {
+			n = len(sl)
+		}
+		v.Elements = make([]*AnnotatedValue, n)
+		for i := range v.Elements {
+			cv, cvOk := listElement(tv, i)
+			cu, cuOk := listElement(ul, i)
+			cs, csOk := listElement(sl, i)
+			v.Elements[i] = newAnnotatedValue(cv, cvOk, cu, cuOk, cs, csOk, !v.Known, v.Sensitive)
+		}
+	default:
+		v.Value = value
+	}
+	return v
+}
+
+func newMaskEncoding(value, mask interface{}, present bool) maskEncoding {
+	if !present {
+		return maskEncoding{form: maskAbsent}
+	}
+	switch mask.(type) {
+	case bool:
+		return maskEncoding{form: maskBool}
+	case map[string]interface{}:
+		if _, ok := value.(map[string]interface{}); ok {
+			return maskEncoding{form: maskContainer}
+		}
+	case []interface{}:
+		if _, ok := value.([]interface{}); ok {
+			return maskEncoding{form: maskContainer}
+		}
+	}
+	return maskEncoding{form: maskRaw, raw: mask}
+}
+
+func listElement(list []interface{}, i int) (interface{}, bool) {
+	if i < len(list) {
+		return list[i], true
+	}
+	return nil, false
+}
+
+// mergedMap returns a map with the keys of both a and b, for use with
+// mergedKeys.
+func mergedMap(a, b map[string]interface{}) map[string]interface{} {
+	ret := make(map[string]interface{}, len(a)+len(b))
+	for k := range a {
+		ret[k] = nil
+	}
+	for k := range b {
+		ret[k] = nil
+	}
+	return ret
+}
+
+// IsNull returns true if the value is known and null.
+func (v *AnnotatedValue) IsNull() bool {
+	return v.Known && v.Value == nil && v.Attributes == nil && v.Elements == nil
+}
+
+// Lookup returns the node at path, or nil if there is none.
+func (v *AnnotatedValue) Lookup(path AttributePath) *AnnotatedValue {
+	for _, step := range path {
+		if v == nil {
+			return nil
+		}
+		switch step := step.(type) {
+		case AttributeStep:
+			v = v.Attributes[string(step)]
+		case KeyStep:
+			v = v.Attributes[string(step)]
+		case IndexStep:
+			if int(step) < 0 || int(step) >= len(v.Elements) {
+				return nil
+			}
+			v = v.Elements[step]
+		}
+	}
+	return v
+}
+
+// JSON converts the tree back to a JSON value and its unknown and
+// sensitive masks. For trees built by NewAnnotatedValue and left
+// unmodified, the original trees are reproduced exactly. Otherwise, the
+// trees are built the way Terraform does: unknown object attributes
+// are omitted from value, and masks only list the marked values.
+func (v *AnnotatedValue) JSON() (value, unknown, sensitive interface{}) {
+	value, _ = v.jsonValue(false)
+	unknown, _ = v.mask(unknownFlag, false, false)
+	sensitive, _ = v.mask(sensitiveFlag, false, false)
+	return value, unknown, sensitive
+}
+
+func (v *AnnotatedValue) jsonValue(inObject bool) (interface{}, bool) {
+	if v.valueAbsent {
+		return nil, false
+	}
+	if !v.decoded && !v.Known {
+		return nil, !inObject
+	}
+
+	switch {
+	case v.Attributes != nil:
+		obj := make(map[string]interface{}, len(v.Attributes))
+		for key, child := range v.Attributes {
+			if cv, ok := child.jsonValue(true); ok {
+				obj[key] = cv
+			}
+		}
+		return obj, true
+	case v.Elements != nil:
+		list := make([]interface{}, 0, len(v.Elements))
+		for _, child := range v.Elements {
+			if cv, ok := child.jsonValue(false); ok {
+				list = append(list, cv)
+			}
+		}
+		return list, true
+	}
+	return v.Value, true
+}
+
+// maskFlag selects one of the two masks of a node.
+type maskFlag func(v *AnnotatedValue) (bool, maskEncoding)
+
+func unknownFlag(v *AnnotatedValue) (bool, maskEncoding) {
+	return !v.Known, v.unknownEnc
+}
+
+func sensitiveFlag(v *AnnotatedValue) (bool, maskEncoding) {
+	return v.Sensitive, v.sensitiveEnc
+}
+
+// mask returns the mask tree of the node selected by flag. inherited is
+// true if a parent was already marked, and inObject if the node is an
+// object attribute. The second result is false if the node is omitted
+// from the mask.
+func (v *AnnotatedValue) mask(flag maskFlag, inherited, inObject bool) (interface{}, bool) {
+	marked, enc := flag(v)
+
+	switch enc.form {
+	case maskAbsent:
+		if !marked || inherited {
+			return nil, false
+		}
+	case maskBool:
+		if marked {
+			return true, true
+		}
+		if !v.anyMarked(flag) {
+			return false, true
+		}
+	case maskRaw:
+		if maskAtPath(enc.raw, nil) == marked {
+			return enc.raw, true
+		}
+	case maskContainer:
+		if marked && !inherited {
+			return true, true
+		}
+		return v.maskChildren(flag, marked), true
+	}
+
+	if marked {
+		if inherited {
+			return nil, false
+		}
+		return true, true
+	}
+	if v.Attributes != nil || v.Elements != nil {
+		return v.maskChildren(flag, false), true
+	}
+	if inObject {
+		return nil, false
+	}
+	return false, true
+}
+
+func (v *AnnotatedValue) maskChildren(flag maskFlag, inherited bool) interface{} {
+	if v.Elements == nil {
+		obj := make(map[string]interface{}, len(v.Attributes))
+		for key, child := range v.Attributes {
+			if m, ok := child.mask(flag, inherited, true); ok {
+				obj[key] = m
+			}
+		}
+		return obj
+	}
+
+	list := make([]interface{}, 0, len(v.Elements))
+	last := -1
+	for i, child := range v.Elements {
+		m, ok := child.mask(flag, inherited, false)
+		if !ok {
+			m = false
+		} else {
+			last = i
+		}
+		list = append(list, m)
+	}
+	return list[:last+1]
+}
+
+// anyMarked returns true if any value nested within v is marked.
+func (v *AnnotatedValue) anyMarked(flag maskFlag) bool {
+	for _, child := range v.Attributes {
+		if marked, _ := flag(child); marked || child.anyMarked(flag) {
+			return true
+		}
+	}
+	for _, child := range v.Elements {
+		if marked, _ := flag(child); marked || child.anyMarked(flag) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangeAfterAnnotated(t *testing.T) {
+	change := &Change{
+		After: map[string]interface{}{
+			"name":  "web",
+			"tags":  map[string]interface{}{"env": "prod"},
+			"disks": []interface{}{nil, map[string]interface{}{"size": float64(10)}},
+		},
+		AfterUnknown: map[string]interface{}{
+			"id":    true,
+			"disks": []interface{}{true, map[string]interface{}{}},
+		},
+		AfterSensitive: map[string]interface{}{
+			"tags": true,
+		},
+	}
+
+	v := change.AfterAnnotated()
+
+	cases := []struct {
+		path      AttributePath
+		known     bool
+		sensitive bool
+		value     interface{}
+	}{
+		{AttributePath{AttributeStep("name")}, true, false, "web"},
+		{AttributePath{AttributeStep("id")}, false, false, nil},
+		{AttributePath{AttributeStep("tags"), KeyStep("env")}, true, true, "prod"},
+		{AttributePath{AttributeStep("disks"), IndexStep(0)}, false, false, nil},
+		{AttributePath{AttributeStep("disks"), IndexStep(1), AttributeStep("size")}, true, false, float64(10)},
+	}
+	for _, tc := range cases {
+		node := v.Lookup(tc.path)
+		if node == nil {
+			t.Fatalf("%s: not found", tc.path)
+		}
+		if node.Known != tc.known || node.Sensitive != tc.sensitive || node.Value != tc.value {
+			t.Fatalf("%s: unexpected node %#v", tc.path, node)
+		}
+	}
+
+	if node := v.Lookup(AttributePath{AttributeStep("missing")}); node != nil {
+		t.Fatalf("expected no node, got %#v", node)
+	}
+
+	value, unknown, sensitive := v.JSON()
+	if diff := cmp.Diff(change.After, value); diff != "" {
+		t.Fatalf("unexpected value (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(change.AfterUnknown, unknown); diff != "" {
+		t.Fatalf("unexpected unknown mask (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(change.AfterSensitive, sensitive); diff != "" {
+		t.Fatalf("unexpected sensitive mask (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestAnnotatedValueJSON_built(t *testing.T) {
+	v := &AnnotatedValue{
+		Known: true,
+		Attributes: map[string]*AnnotatedValue{
+			"id":       {Known: false},
+			"name":     {Known: true, Value: "web"},
+			"password": {Known: true, Sensitive: true, Value: "secret"},
+			"list": {Known: true, Elements: []*AnnotatedValue{
+				{Known: true, Value: "a"},
+				{Known: false},
+			}},
+		},
+	}
+
+	value, unknown, sensitive := v.JSON()
+
+	expectedValue := map[string]interface{}{
+		"name":     "web",
+		"password": "secret",
+		"list":     []interface{}{"a", nil},
+	}
+	expectedUnknown := map[string]interface{}{
+		"id":   true,
+		"list": []interface{}{false, true},
+	}
+	expectedSensitive := map[string]interface{}{
+		"password": true,
+		"list":     []interface{}{false, false},
+	}
+
+	if diff := cmp.Diff(expectedValue, value); diff != "" {
+		t.Fatalf("unexpected value (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedUnknown, unknown); diff != "" {
+		t.Fatalf("unexpected unknown mask (-expected +actual):\n%s", diff)
+	}
+	if diff := cmp.Diff(expectedSensitive, sensitive); diff != "" {
+		t.Fatalf("unexpected sensitive mask (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestAnnotatedValue_roundTripFixtures(t *testing.T) {
+	plans, err := filepath.Glob("testdata/*/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	check := func(t *testing.T, name string, value, unknown, sensitive interface{}) {
+		t.Helper()
+		v, u, s := NewAnnotatedValue(value, unknown, sensitive).JSON()
+		if diff := cmp.Diff([]interface{}{value, unknown, sensitive}, []interface{}{v, u, s}); diff != "" {
+			t.Fatalf("%s: round trip mismatch (-expected +actual):\n%s", name, diff)
+		}
+	}
+
+	for _, path := range plans {
+		t.Run(filepath.Base(filepath.Dir(path)), func(t *testing.T) {
+			f, err := os.Open(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			var plan *Plan
+			if err := json.NewDecoder(f).Decode(&plan); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, rc := range append(plan.ResourceChanges, plan.ResourceDrift...) {
+				if rc.Change == nil {
+					continue
+				}
+				check(t, rc.Address+" before", rc.Change.Before, nil, rc.Change.BeforeSensitive)
+				check(t, rc.Address+" after", rc.Change.After, rc.Change.AfterUnknown, rc.Change.AfterSensitive)
+			}
+
+			values := []*StateValues{plan.PlannedValues}
+			if plan.PriorState != nil {
+				values = append(values, plan.PriorState.Values)
+			}
+			for _, values := range values {
+				if values == nil {
+					continue
+				}
+				_ = values.RootModule.Walk(func(_ *StateModule, r *StateResource) error {
+					var attrs interface{}
+					if r.AttributeValues != nil {
+						attrs = r.AttributeValues
+					}
+					check(t, r.Address, attrs, nil, r.SensitiveValues)
+					return nil
+				})
+			}
+		})
+	}
+}
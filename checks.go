@@ -3,6 +3,8 @@
 
 package tfjson
 
+import "encoding/json"
+
 // CheckKind is a string representation of the type of conditional check
 // referenced in a check result.
 type CheckKind string
@@ -95,6 +97,14 @@ type CheckDynamicAddress struct {
 	InstanceKey interface{} `json:"instance_key,omitempty"`
 }
 
+// MarshalJSON implements json.Marshaler for CheckDynamicAddress.
+func (a *CheckDynamicAddress) MarshalJSON() ([]byte, error) {
+	type rawCheckDynamicAddress CheckDynamicAddress
+	raw := rawCheckDynamicAddress(*a)
+	raw.InstanceKey = jsonNumbers(raw.InstanceKey)
+	return json.Marshal(&raw)
+}
+
 // CheckResultStatic is the container for a "checkable object".
 //
 // A "checkable object" is a resource or data source, an output, or a check
@@ -0,0 +1,145 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValuesEqual returns true if the JSON values a and b are equal.
+// Numbers decoded as *big.Float are compared by value.
+//
+// If schema is not nil, it is the schema of the object that a and b
+// are values of, and the collections it types as sets, either with
+// SchemaNestingModeSet or with a cty set type, are compared regardless
+// of the order of their elements.
+func ValuesEqual(a, b interface{}, schema *Schema) bool {
+	return valuesEqual(schemaType(schema), a, b)
+}
+
+// schemaType returns the type implied by schema, or
+// cty.DynamicPseudoType if it is nil.
+func schemaType(schema *Schema) cty.Type {
+	if schema == nil || schema.Block == nil {
+		return cty.DynamicPseudoType
+	}
+	return schema.Block.ImpliedType()
+}
+
+// childType returns the type of the value found at step within a value
+// of type ty, or cty.DynamicPseudoType if it is not known.
+func childType(ty cty.Type, step AttributePathStep) cty.Type {
+	switch {
+	case ty.IsObjectType():
+		var name string
+		switch step := step.(type) {
+		case AttributeStep:
+			name = string(step)
+		case KeyStep:
+			name = string(step)
+		}
+		if ty.HasAttribute(name) {
+			return ty.AttributeType(name)
+		}
+	case ty.IsMapType(), ty.IsListType(), ty.IsSetType():
+		return ty.ElementType()
+	case ty.IsTupleType():
+		if i, ok := step.(IndexStep); ok && int(i) >= 0 && int(i) < ty.Length() {
+			return ty.TupleElementType(int(i))
+		}
+	}
+	return cty.DynamicPseudoType
+}
+
+// typeAtPath returns the type of the value found at path within a
+// value of type ty, or cty.DynamicPseudoType if it is not known.
+func typeAtPath(ty cty.Type, path AttributePath) cty.Type {
+	for _, step := range path {
+		ty = childType(ty, step)
+	}
+	return ty
+}
+
+// jsonValuesEqual returns true if the JSON values a and b are equal,
+// comparing all collections in order.
+func jsonValuesEqual(a, b interface{}) bool {
+	return valuesEqual(cty.DynamicPseudoType, a, b)
+}
+
+// valuesEqual returns true if the JSON values a and b of type ty are
+// equal, comparing sets regardless of order.
+func valuesEqual(ty cty.Type, a, b interface{}) bool {
+	switch a := a.(type) {
+	case *big.Float:
+		b, ok := b.(*big.Float)
+		if !ok || a == nil || b == nil {
+			return ok && a == b
+		}
+		return a.Cmp(b) == 0
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || (a == nil) != (b == nil) || len(a) != len(b) {
+			return false
+		}
+		for k, av := range a {
+			bv, ok := b[k]
+			if !ok || !valuesEqual(childType(ty, AttributeStep(k)), av, bv) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || (a == nil) != (b == nil) || len(a) != len(b) {
+			return false
+		}
+		if ty.IsSetType() {
+			matchedA, _ := matchSetElements(ty.ElementType(), a, b)
+			for _, matched := range matchedA {
+				if !matched {
+					return false
+				}
+			}
+			return true
+		}
+		for i := range a {
+			if !valuesEqual(childType(ty, IndexStep(i)), a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// matchSetElements pairs each element of a with an equal element of b,
+// regardless of order, and returns which elements of a and b found a
+// match. Each element is matched at most once, so duplicates are
+// counted.
+func matchSetElements(ety cty.Type, a, b []interface{}) (matchedA, matchedB []bool) {
+	matchedA = make([]bool, len(a))
+	matchedB = make([]bool, len(b))
+	for i, av := range a {
+		for j, bv := range b {
+			if !matchedB[j] && valuesEqual(ety, av, bv) {
+				matchedA[i], matchedB[j] = true, true
+				break
+			}
+		}
+	}
+	return matchedA, matchedB
+}
+
+// setContains returns true if elems holds an element equal to v.
+func setContains(ety cty.Type, elems []interface{}, v interface{}) bool {
+	for _, elem := range elems {
+		if valuesEqual(ety, elem, v) {
+			return true
+		}
+	}
+	return false
+}
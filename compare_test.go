@@ -0,0 +1,171 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testSetSchema() *Schema {
+	return &Schema{
+		Block: &SchemaBlock{
+			Attributes: map[string]*SchemaAttribute{
+				"name":          {AttributeType: cty.String, Optional: true},
+				"ports":         {AttributeType: cty.List(cty.Number), Optional: true},
+				"cidr_blocks":   {AttributeType: cty.Set(cty.String), Optional: true},
+				"dynamic_value": {AttributeType: cty.DynamicPseudoType, Optional: true},
+			},
+			NestedBlocks: map[string]*SchemaBlockType{
+				"ingress": {
+					NestingMode: SchemaNestingModeSet,
+					Block: &SchemaBlock{
+						Attributes: map[string]*SchemaAttribute{
+							"port":        {AttributeType: cty.Number, Required: true},
+							"cidr_blocks": {AttributeType: cty.Set(cty.String), Optional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	schema := testSetSchema()
+
+	cases := map[string]struct {
+		a, b        interface{}
+		ordered     bool
+		setsAreSets bool
+	}{
+		"reordered set attribute": {
+			a:           map[string]interface{}{"cidr_blocks": []interface{}{"a", "b"}},
+			b:           map[string]interface{}{"cidr_blocks": []interface{}{"b", "a"}},
+			setsAreSets: true,
+		},
+		"reordered set block with nested set": {
+			a: map[string]interface{}{"ingress": []interface{}{
+				map[string]interface{}{"port": float64(80), "cidr_blocks": []interface{}{"a", "b"}},
+				map[string]interface{}{"port": float64(443), "cidr_blocks": []interface{}{"c"}},
+			}},
+			b: map[string]interface{}{"ingress": []interface{}{
+				map[string]interface{}{"port": float64(443), "cidr_blocks": []interface{}{"c"}},
+				map[string]interface{}{"port": float64(80), "cidr_blocks": []interface{}{"b", "a"}},
+			}},
+			setsAreSets: true,
+		},
+		"reordered list": {
+			a: map[string]interface{}{"ports": []interface{}{float64(1), float64(2)}},
+			b: map[string]interface{}{"ports": []interface{}{float64(2), float64(1)}},
+		},
+		"reordered dynamic value": {
+			a: map[string]interface{}{"dynamic_value": []interface{}{"a", "b"}},
+			b: map[string]interface{}{"dynamic_value": []interface{}{"b", "a"}},
+		},
+		"different set elements": {
+			a: map[string]interface{}{"cidr_blocks": []interface{}{"a", "b"}},
+			b: map[string]interface{}{"cidr_blocks": []interface{}{"a", "c"}},
+		},
+		"different set sizes": {
+			a: map[string]interface{}{"cidr_blocks": []interface{}{"a", "a"}},
+			b: map[string]interface{}{"cidr_blocks": []interface{}{"a"}},
+		},
+		"identical": {
+			a:           map[string]interface{}{"name": "x", "ports": []interface{}{float64(1)}},
+			b:           map[string]interface{}{"name": "x", "ports": []interface{}{float64(1)}},
+			ordered:     true,
+			setsAreSets: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if actual := ValuesEqual(tc.a, tc.b, nil); actual != tc.ordered {
+				t.Errorf("without schema: expected %t, got %t", tc.ordered, actual)
+			}
+			if actual := ValuesEqual(tc.a, tc.b, schema); actual != tc.setsAreSets {
+				t.Errorf("with schema: expected %t, got %t", tc.setsAreSets, actual)
+			}
+		})
+	}
+}
+
+func TestChangeDiffWithSchema(t *testing.T) {
+	change := &Change{
+		Actions: Actions{ActionUpdate},
+		Before: map[string]interface{}{
+			"cidr_blocks": []interface{}{"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16"},
+			"ports":       []interface{}{float64(1), float64(2)},
+			"ingress": []interface{}{
+				map[string]interface{}{"port": float64(22), "cidr_blocks": []interface{}{"a"}},
+				map[string]interface{}{"port": float64(80), "cidr_blocks": []interface{}{"a", "b"}},
+			},
+		},
+		After: map[string]interface{}{
+			"cidr_blocks": []interface{}{"192.168.0.0/16", "10.0.0.0/8"},
+			"ports":       []interface{}{float64(2), float64(1)},
+			"ingress": []interface{}{
+				map[string]interface{}{"port": float64(80), "cidr_blocks": []interface{}{"b", "a"}},
+				map[string]interface{}{"port": float64(443), "cidr_blocks": []interface{}{"a"}},
+			},
+		},
+	}
+
+	type entry struct {
+		Path          string
+		Action        AttributeAction
+		Before, After interface{}
+	}
+
+	var actual []entry
+	for _, ac := range change.DiffWithSchema(testSetSchema()) {
+		actual = append(actual, entry{ac.Path.String(), ac.Action, ac.Before, ac.After})
+	}
+
+	expected := []entry{
+		{"cidr_blocks[1]", AttributeActionRemove, "172.16.0.0/12", nil},
+		{"ingress[0]", AttributeActionRemove, map[string]interface{}{"port": float64(22), "cidr_blocks": []interface{}{"a"}}, nil},
+		{"ingress[1]", AttributeActionAdd, nil, map[string]interface{}{"port": float64(443), "cidr_blocks": []interface{}{"a"}}},
+		{"ports[0]", AttributeActionModify, float64(1), float64(2)},
+		{"ports[1]", AttributeActionModify, float64(2), float64(1)},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected diff (-expected +actual):\n%s", diff)
+	}
+
+	if diff := change.Diff(); len(diff) <= len(expected) {
+		t.Fatalf("expected the ordered diff to report reordered set elements, got %d changes", len(diff))
+	}
+}
+
+func TestChangeDiffWithSchema_sameIndex(t *testing.T) {
+	change := &Change{
+		Actions: Actions{ActionUpdate},
+		Before:  map[string]interface{}{"cidr_blocks": []interface{}{"a", "b"}},
+		After:   map[string]interface{}{"cidr_blocks": []interface{}{"a", "c"}},
+	}
+
+	// The elements are not related, so they are reported as a removal
+	// and an addition rather than a modification.
+	type entry struct {
+		Path          string
+		Action        AttributeAction
+		Before, After interface{}
+	}
+	var actual []entry
+	for _, ac := range change.DiffWithSchema(testSetSchema()) {
+		actual = append(actual, entry{ac.Path.String(), ac.Action, ac.Before, ac.After})
+	}
+
+	expected := []entry{
+		{"cidr_blocks[1]", AttributeActionRemove, "b", nil},
+		{"cidr_blocks[1]", AttributeActionAdd, nil, "c"},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+}
@@ -10,6 +10,10 @@ import (
 
 // Config represents the complete configuration source.
 type Config struct {
+	// decodeOptions controls how the configuration is decoded, including
+	// its expressions. Set it using Config.SetDecodeOptions.
+	decodeOptions DecodeOptions
+
 	// A map of all provider instances across all modules in the
 	// configuration.
 	//
@@ -32,16 +36,31 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// SetDecodeOptions sets the options used when the Config is decoded.
+// They also apply to the expressions of the configuration, and to
+// variable defaults.
+func (c *Config) SetDecodeOptions(opts DecodeOptions) {
+	c.decodeOptions = opts
+}
+
 func (c *Config) UnmarshalJSON(b []byte) error {
 	type rawConfig Config
 	var config rawConfig
 
-	err := json.Unmarshal(b, &config)
-	if err != nil {
+	opts := c.decodeOptions
+	if err := opts.decode(b, &config); err != nil {
 		return err
 	}
 
 	*c = *(*Config)(&config)
+	c.decodeOptions = opts
+
+	if err := c.decodeExpressions(b); err != nil {
+		return err
+	}
+	if opts.Numbers == NumberBigFloat {
+		toBigFloats(c)
+	}
 
 	return c.Validate()
 }
@@ -158,6 +177,14 @@ type ConfigVariable struct {
 	Sensitive bool `json:"sensitive,omitempty"`
 }
 
+// MarshalJSON implements json.Marshaler for ConfigVariable.
+func (v *ConfigVariable) MarshalJSON() ([]byte, error) {
+	type rawConfigVariable ConfigVariable
+	raw := rawConfigVariable(*v)
+	raw.Default = jsonNumbers(raw.Default)
+	return json.Marshal(&raw)
+}
+
 // ConfigProvisioner describes a provisioner declared in a resource
 // configuration.
 type ConfigProvisioner struct {
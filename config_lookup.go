@@ -0,0 +1,121 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ModuleCalls returns the chain of module calls leading from the root
+// module to the module at path, in order. The chain is empty for the
+// root module.
+func (c *Config) ModuleCalls(path ModulePath) ([]*ModuleCall, error) {
+	if c == nil || c.RootModule == nil {
+		return nil, errors.New("config has no root module")
+	}
+
+	var calls []*ModuleCall
+	module := c.RootModule
+	for i, name := range path {
+		call, ok := module.ModuleCalls[name]
+		if !ok || call == nil {
+			return nil, fmt.Errorf("module call %q not found in %s", name, moduleDisplayName(path[:i]))
+		}
+		if call.Module == nil {
+			return nil, fmt.Errorf("module call %q in %s has no module", name, moduleDisplayName(path[:i]))
+		}
+		calls = append(calls, call)
+		module = call.Module
+	}
+
+	return calls, nil
+}
+
+// Module returns the configuration of the module at path.
+func (c *Config) Module(path ModulePath) (*ConfigModule, error) {
+	calls, err := c.ModuleCalls(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(calls) == 0 {
+		return c.RootModule, nil
+	}
+	return calls[len(calls)-1].Module, nil
+}
+
+// LookupResource returns the resource declared at the supplied config
+// address, along with the chain of module calls leading from the root
+// module to the module declaring it.
+func (c *Config) LookupResource(addr ConfigResourceAddress) (*ConfigResource, []*ModuleCall, error) {
+	calls, err := c.ModuleCalls(addr.Module)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	module := c.RootModule
+	if len(calls) > 0 {
+		module = calls[len(calls)-1].Module
+	}
+
+	relative := addr.RelativeString()
+	for _, r := range module.Resources {
+		if r == nil {
+			continue
+		}
+		if r.Address == relative || (r.Type == addr.Type && r.Name == addr.Name && resourceModeOrManaged(r.Mode) == resourceModeOrManaged(addr.Mode)) {
+			return r, calls, nil
+		}
+	}
+
+	return nil, nil, fmt.Errorf("resource %s not found in configuration", addr)
+}
+
+// LookupResourceInstance returns the resource declaring the resource
+// instance at addr, along with the chain of module calls leading to
+// it. All module and resource instance keys are ignored.
+func (c *Config) LookupResourceInstance(addr ResourceAddress) (*ConfigResource, []*ModuleCall, error) {
+	return c.LookupResource(addr.ConfigAddress())
+}
+
+// LookupResourceChange returns the resource declaring the object of
+// the supplied resource change, along with the chain of module calls
+// leading to it.
+func (c *Config) LookupResourceChange(rc *ResourceChange) (*ConfigResource, []*ModuleCall, error) {
+	if rc == nil {
+		return nil, nil, errors.New("resource change is nil")
+	}
+	addr, err := rc.ParseAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.LookupResourceInstance(addr)
+}
+
+// LookupStateResource returns the resource declaring the supplied
+// state resource, along with the chain of module calls leading to it.
+func (c *Config) LookupStateResource(r *StateResource) (*ConfigResource, []*ModuleCall, error) {
+	if r == nil {
+		return nil, nil, errors.New("state resource is nil")
+	}
+	addr, err := r.ParseAddress()
+	if err != nil {
+		return nil, nil, err
+	}
+	return c.LookupResourceInstance(addr)
+}
+
+func resourceModeOrManaged(m ResourceMode) ResourceMode {
+	if m == "" {
+		return ManagedResourceMode
+	}
+	return m
+}
+
+func moduleDisplayName(path ModulePath) string {
+	if path.IsRoot() {
+		return "root module"
+	}
+	return path.String()
+}
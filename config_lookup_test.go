@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import "testing"
+
+func TestConfigLookupResourceChange(t *testing.T) {
+	plan := testLoadPlan(t, "deep_module")
+
+	r, calls, err := plan.Config.LookupResourceChange(plan.ResourceChanges[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Address != "null_resource.baz" {
+		t.Fatalf("unexpected resource %q", r.Address)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("expected 2 module calls, got %d", len(calls))
+	}
+	if calls[0] != plan.Config.RootModule.ModuleCalls["foo"] {
+		t.Fatal("expected first call to be module.foo")
+	}
+	if calls[1] != calls[0].Module.ModuleCalls["bar"] {
+		t.Fatal("expected second call to be module.foo.module.bar")
+	}
+}
+
+func TestConfigLookupResourceInstance(t *testing.T) {
+	baz := &ConfigResource{Address: "data.null_data_source.baz", Mode: DataResourceMode, Type: "null_data_source", Name: "baz"}
+	sub := &ModuleCall{
+		Source: "./sub",
+		Module: &ConfigModule{
+			Resources: []*ConfigResource{baz},
+		},
+	}
+	net := &ModuleCall{
+		Source: "./net",
+		Module: &ConfigModule{
+			ModuleCalls: map[string]*ModuleCall{"sub": sub},
+		},
+	}
+	config := &Config{
+		RootModule: &ConfigModule{
+			ModuleCalls: map[string]*ModuleCall{"net": net},
+		},
+	}
+
+	addr, err := ParseResourceAddress(`module.net["eu"].module.sub[1].data.null_data_source.baz["x"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, calls, err := config.LookupResourceInstance(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r != baz {
+		t.Fatalf("unexpected resource %#v", r)
+	}
+	if len(calls) != 2 || calls[0] != net || calls[1] != sub {
+		t.Fatalf("unexpected module calls %#v", calls)
+	}
+
+	for _, in := range []string{
+		`module.net["eu"].module.sub[1].null_data_source.baz`,
+		`module.net["eu"].module.other.data.null_data_source.baz`,
+		`data.null_data_source.baz`,
+	} {
+		addr, err := ParseResourceAddress(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := config.LookupResourceInstance(addr); err == nil {
+			t.Errorf("expected error looking up %s", in)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResolveProviderConfig returns the provider configuration effectively
+// used by the resource r, declared in the module at path.
+//
+// Terraform 1.x already resolves provider inheritance and providers
+// passed explicitly to module calls using "providers = {}" when
+// computing ConfigResource.ProviderConfigKey, in which case the key is
+// found directly in ProviderConfigs. Keys written by older versions
+// refer to the module the resource is declared in, using only the
+// module call name. These are resolved by looking for a matching
+// configuration in the declaring module, then in each of its parents
+// for default (non-aliased) configurations, which are inherited
+// implicitly.
+//
+// A default configuration without a provider block is not recorded in
+// ProviderConfigs. If none is found for a non-aliased provider, an
+// implied empty configuration holding only the provider name is
+// returned, as Terraform uses one in that case.
+func (c *Config) ResolveProviderConfig(path ModulePath, r *ConfigResource) (*ProviderConfig, error) {
+	if r == nil {
+		return nil, fmt.Errorf("resource is nil")
+	}
+
+	key := r.ProviderConfigKey
+	if key == "" {
+		// The implied provider is the resource type prefix.
+		key = strings.SplitN(r.Type, "_", 2)[0]
+	}
+
+	if pc, ok := c.ProviderConfigs[key]; ok && pc != nil {
+		return pc, nil
+	}
+
+	local := key
+	if i := strings.LastIndexByte(key, ':'); i >= 0 {
+		local = key[i+1:]
+	}
+	name, alias := local, ""
+	if i := strings.IndexByte(local, '.'); i >= 0 {
+		name, alias = local[:i], local[i+1:]
+	}
+
+	for depth := len(path); depth >= 0; depth-- {
+		modulePath := path[:depth]
+
+		if !modulePath.IsRoot() {
+			if pc, ok := c.ProviderConfigs[modulePath.String()+":"+local]; ok && pc != nil {
+				return pc, nil
+			}
+		} else if pc, ok := c.ProviderConfigs[local]; ok && pc != nil {
+			return pc, nil
+		}
+
+		for _, pc := range c.ProviderConfigs {
+			if pc == nil || !moduleAddressMatches(pc.ModuleAddress, modulePath) {
+				continue
+			}
+			if (pc.Name == name && pc.Alias == alias) || (pc.Alias == "" && pc.Name == local) {
+				return pc, nil
+			}
+		}
+
+		if alias != "" {
+			// Aliased configurations are never inherited implicitly.
+			break
+		}
+	}
+
+	if alias == "" {
+		return &ProviderConfig{Name: name}, nil
+	}
+	return nil, fmt.Errorf("no provider configuration %q found for %s", local, ConfigResourceAddress{
+		Module: path,
+		Mode:   r.Mode,
+		Type:   r.Type,
+		Name:   r.Name,
+	})
+}
+
+// ResolveResourceProviderConfig returns the provider configuration
+// effectively used by the resource instance at addr. See
+// ResolveProviderConfig for details.
+func (c *Config) ResolveResourceProviderConfig(addr ResourceAddress) (*ProviderConfig, error) {
+	r, _, err := c.LookupResourceInstance(addr)
+	if err != nil {
+		return nil, err
+	}
+	return c.ResolveProviderConfig(addr.Module.Module(), r)
+}
+
+// moduleAddressMatches returns true if the module address recorded in
+// ProviderConfig.ModuleAddress refers to the module at path. Older
+// versions of Terraform recorded only the module call names, ie: "foo"
+// rather than "module.foo".
+func moduleAddressMatches(addr string, path ModulePath) bool {
+	if addr == "" || path.IsRoot() {
+		return addr == "" && path.IsRoot()
+	}
+	if strings.HasPrefix(addr, "module.") {
+		return addr == path.String()
+	}
+	return addr == strings.Join(path, ".") || addr == path[len(path)-1]
+}
@@ -0,0 +1,99 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import "testing"
+
+func TestConfigResolveResourceProviderConfig(t *testing.T) {
+	cases := []struct {
+		fixture  string
+		address  string
+		expected string
+	}{
+		// Legacy keys, resolved through the declaring module.
+		{"basic", "module.foo.null_resource.aliased", "foo:null.aliased"},
+		{"basic", "module.foo.null_resource.foo", "null"},
+		{"110_basic", "module.foo.null_resource.foo", "module.foo:null"},
+		{"110_basic", "null_resource.bar", "null"},
+		// Keys already resolved by Terraform.
+		{"120_basic", "module.foo.null_resource.aliased", "null"},
+		{"has_checks", `module.files.local_file.foo["file1.txt"]`, "module.files:local"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.fixture+"/"+tc.address, func(t *testing.T) {
+			plan := testLoadPlan(t, tc.fixture)
+
+			addr, err := ParseResourceAddress(tc.address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := plan.Config.ResolveResourceProviderConfig(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if expected := plan.Config.ProviderConfigs[tc.expected]; actual != expected {
+				t.Fatalf("expected provider config %q, got %#v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestConfigResolveResourceProviderConfig_implied(t *testing.T) {
+	cases := []struct {
+		fixture string
+		address string
+	}{
+		{"explicit_null", "null_resource.foo"},
+		{"deep_module", "module.foo.module.bar.null_resource.baz"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.fixture+"/"+tc.address, func(t *testing.T) {
+			plan := testLoadPlan(t, tc.fixture)
+
+			addr, err := ParseResourceAddress(tc.address)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual, err := plan.Config.ResolveResourceProviderConfig(addr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual.Name != "null" || actual.Alias != "" || actual.Expressions != nil {
+				t.Fatalf("expected an implied null provider config, got %#v", actual)
+			}
+		})
+	}
+}
+
+func TestConfigResolveProviderConfig_aliasNotInherited(t *testing.T) {
+	config := &Config{
+		ProviderConfigs: map[string]*ProviderConfig{
+			"aws.east": {Name: "aws", Alias: "east"},
+		},
+	}
+	r := &ConfigResource{
+		Address:           "aws_instance.foo",
+		Mode:              ManagedResourceMode,
+		Type:              "aws_instance",
+		Name:              "foo",
+		ProviderConfigKey: "child:aws.east",
+	}
+
+	if _, err := config.ResolveProviderConfig(ModulePath{"child"}, r); err == nil {
+		t.Fatal("expected error resolving aliased provider from parent module")
+	}
+
+	pc, err := config.ResolveProviderConfig(nil, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pc != config.ProviderConfigs["aws.east"] {
+		t.Fatalf("unexpected provider config %#v", pc)
+	}
+}
@@ -0,0 +1,431 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ConformanceErrorKind describes how a resource value fails to conform
+// to its schema.
+type ConformanceErrorKind string
+
+const (
+	// ConformanceSchemaNotFound indicates that no schema could be
+	// found for the resource. Err holds the lookup error.
+	ConformanceSchemaNotFound ConformanceErrorKind = "schema_not_found"
+
+	// ConformanceSchemaVersion indicates that the schema version
+	// recorded for the resource differs from the version of its
+	// schema. The values are still checked against the schema.
+	ConformanceSchemaVersion ConformanceErrorKind = "schema_version"
+
+	// ConformanceMissingRequired indicates that a required attribute
+	// is absent or null.
+	ConformanceMissingRequired ConformanceErrorKind = "missing_required"
+
+	// ConformanceWrongType indicates that a value does not have the
+	// JSON type implied by the schema.
+	ConformanceWrongType ConformanceErrorKind = "wrong_type"
+
+	// ConformanceTooFewItems and ConformanceTooManyItems indicate that
+	// a nested block or nested attribute has fewer items than its
+	// MinItems, or more items than its MaxItems.
+	ConformanceTooFewItems  ConformanceErrorKind = "too_few_items"
+	ConformanceTooManyItems ConformanceErrorKind = "too_many_items"
+
+	// ConformanceUnknownAttribute indicates that an object has an
+	// attribute or nested block that is not part of the schema.
+	ConformanceUnknownAttribute ConformanceErrorKind = "unknown_attribute"
+
+	// ConformanceDeprecated indicates that a deprecated attribute or
+	// nested block is set. Deprecated attributes that are only computed
+	// are not reported. This does not make the value invalid, but
+	// is reported so that it can be surfaced as a warning.
+	ConformanceDeprecated ConformanceErrorKind = "deprecated"
+)
+
+// ConformanceError describes a single way in which the values of a
+// resource fail to conform to its schema. Values are never included in
+// the error, as they may be sensitive.
+type ConformanceError struct {
+	// The absolute address of the resource instance.
+	Address string
+
+	// The path of the offending value within the resource. It is
+	// empty for errors about the resource as a whole.
+	Path AttributePath
+
+	// The kind of error.
+	Kind ConformanceErrorKind
+
+	// A human-readable description of the error.
+	Detail string
+
+	// The underlying error, for ConformanceSchemaNotFound and
+	// ConformanceSchemaVersion.
+	Err error
+}
+
+func (e *ConformanceError) Error() string {
+	if len(e.Path) == 0 {
+		return fmt.Sprintf("%s: %s", e.Address, e.Detail)
+	}
+	return fmt.Sprintf("%s: %s: %s", e.Address, e.Path, e.Detail)
+}
+
+func (e *ConformanceError) Unwrap() error {
+	return e.Err
+}
+
+// CheckConformance checks the AttributeValues of the resources in
+// PlannedValues, then in PriorState, against their schemas. Values
+// that are unknown according to the matching ResourceChange, and so
+// omitted from PlannedValues, are not checked.
+func (p *Plan) CheckConformance(schemas *ProviderSchemas) []*ConformanceError {
+	if p == nil {
+		return nil
+	}
+
+	var errs []*ConformanceError
+	if p.PlannedValues != nil {
+		idx := p.Index()
+		_ = p.PlannedValues.RootModule.Walk(func(_ *StateModule, r *StateResource) error {
+			var unknown interface{}
+			if rc := idx.ResourceChange(indexKey(r.Address, r.DeposedKey)); rc != nil && rc.Change != nil {
+				unknown = rc.Change.AfterUnknown
+			}
+			errs = append(errs, schemas.checkStateResource(r, unknown)...)
+			return nil
+		})
+	}
+	return append(errs, p.PriorState.CheckConformance(schemas)...)
+}
+
+// CheckConformance checks the AttributeValues of all the resources of
+// the state against their schemas.
+func (s *State) CheckConformance(schemas *ProviderSchemas) []*ConformanceError {
+	if s == nil || s.Values == nil {
+		return nil
+	}
+
+	var errs []*ConformanceError
+	_ = s.Values.RootModule.Walk(func(_ *StateModule, r *StateResource) error {
+		errs = append(errs, schemas.checkStateResource(r, nil)...)
+		return nil
+	})
+	return errs
+}
+
+// CheckConformance checks the AttributeValues of a single resource
+// against its schema. unknown is an optional mask following the
+// conventions of Change.AfterUnknown, marking values that are not
+// checked.
+func (p *ProviderSchemas) CheckConformance(r *StateResource, unknown interface{}) []*ConformanceError {
+	if r == nil {
+		return nil
+	}
+	return p.checkStateResource(r, unknown)
+}
+
+func (p *ProviderSchemas) checkStateResource(r *StateResource, unknown interface{}) []*ConformanceError {
+	c := &conformanceChecker{address: r.Address, unknown: unknown}
+
+	schema, err := p.StateResourceSchema(r)
+	switch err.(type) {
+	case nil:
+	case *SchemaVersionMismatchError:
+		c.errs = append(c.errs, &ConformanceError{
+			Address: r.Address,
+			Kind:    ConformanceSchemaVersion,
+			Detail:  err.Error(),
+			Err:     err,
+		})
+	default:
+		return []*ConformanceError{{
+			Address: r.Address,
+			Kind:    ConformanceSchemaNotFound,
+			Detail:  err.Error(),
+			Err:     err,
+		}}
+	}
+
+	var values interface{}
+	if r.AttributeValues != nil {
+		values = r.AttributeValues
+	}
+	c.block(nil, schema.Block, values)
+	return c.errs
+}
+
+// conformanceChecker accumulates the conformance errors of a single
+// resource.
+type conformanceChecker struct {
+	address string
+	unknown interface{}
+	errs    []*ConformanceError
+}
+
+func (c *conformanceChecker) report(path AttributePath, kind ConformanceErrorKind, format string, args ...interface{}) {
+	c.errs = append(c.errs, &ConformanceError{
+		Address: c.address,
+		Path:    path,
+		Kind:    kind,
+		Detail:  fmt.Sprintf(format, args...),
+	})
+}
+
+// skip returns true if the value at path is unknown or null, in which
+// case there is nothing to check within it.
+func (c *conformanceChecker) skip(path AttributePath, v interface{}) bool {
+	return v == nil || maskAtPath(c.unknown, path)
+}
+
+func (c *conformanceChecker) block(path AttributePath, b *SchemaBlock, v interface{}) {
+	if c.skip(path, v) || b == nil {
+		return
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		c.wrongType(path, "object", v)
+		return
+	}
+
+	for _, name := range objectNames(obj, b.Attributes, b.NestedBlocks) {
+		step := AttributeStep(name)
+		if attr, ok := b.Attributes[name]; ok {
+			c.attribute(path.child(step), attr, obj[name])
+			continue
+		}
+		if bt, ok := b.NestedBlocks[name]; ok {
+			c.nestedBlock(path.child(step), bt, obj[name])
+			continue
+		}
+		c.report(path.child(step), ConformanceUnknownAttribute, "unsupported attribute or block %q", name)
+	}
+}
+
+func (c *conformanceChecker) attribute(path AttributePath, attr *SchemaAttribute, v interface{}) {
+	if maskAtPath(c.unknown, path) {
+		return
+	}
+	if v == nil {
+		if attr.Required {
+			c.report(path, ConformanceMissingRequired, "required attribute is not set")
+		}
+		return
+	}
+	// A computed-only attribute is set by the provider rather than the
+	// configuration, so there is nothing to warn about.
+	if attr.Deprecated && (attr.Optional || attr.Required) {
+		c.report(path, ConformanceDeprecated, "attribute is deprecated")
+	}
+
+	nt := attr.AttributeNestedType
+	if nt == nil {
+		c.typed(path, attr.ImpliedType(), v)
+		return
+	}
+	c.nested(path, nt.NestingMode, nt.MinItems, nt.MaxItems, v, func(path AttributePath, v interface{}) {
+		if c.skip(path, v) {
+			return
+		}
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			c.wrongType(path, "object", v)
+			return
+		}
+		for _, name := range objectNames(obj, nt.Attributes, nil) {
+			step := AttributeStep(name)
+			if attr, ok := nt.Attributes[name]; ok {
+				c.attribute(path.child(step), attr, obj[name])
+				continue
+			}
+			c.report(path.child(step), ConformanceUnknownAttribute, "unsupported attribute %q", name)
+		}
+	})
+}
+
+func (c *conformanceChecker) nestedBlock(path AttributePath, bt *SchemaBlockType, v interface{}) {
+	if c.skip(path, v) || bt == nil {
+		return
+	}
+	if bt.Block != nil && bt.Block.Deprecated && !isEmptyContainer(v) {
+		c.report(path, ConformanceDeprecated, "block is deprecated")
+	}
+	c.nested(path, bt.NestingMode, bt.MinItems, bt.MaxItems, v, func(path AttributePath, v interface{}) {
+		c.block(path, bt.Block, v)
+	})
+}
+
+// nested checks the collection of a nested block or nested attribute
+// type according to its nesting mode, calling elem for each of the
+// objects it contains.
+func (c *conformanceChecker) nested(path AttributePath, mode SchemaNestingMode, minItems, maxItems uint64, v interface{}, elem func(AttributePath, interface{})) {
+	if c.skip(path, v) {
+		return
+	}
+
+	var count int
+	switch mode {
+	case SchemaNestingModeSingle, SchemaNestingModeGroup:
+		elem(path, v)
+		return
+	case SchemaNestingModeList, SchemaNestingModeSet:
+		list, ok := v.([]interface{})
+		if !ok {
+			c.wrongType(path, "array", v)
+			return
+		}
+		for i, ev := range list {
+			elem(path.child(IndexStep(i)), ev)
+		}
+		count = len(list)
+	case SchemaNestingModeMap:
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			c.wrongType(path, "object", v)
+			return
+		}
+		for _, key := range mergedKeys(obj, nil) {
+			elem(path.child(KeyStep(key)), obj[key])
+		}
+		count = len(obj)
+	default:
+		return
+	}
+
+	if minItems > 0 && uint64(count) < minItems {
+		c.report(path, ConformanceTooFewItems, "at least %d items are required, got %d", minItems, count)
+	}
+	if maxItems > 0 && uint64(count) > maxItems {
+		c.report(path, ConformanceTooManyItems, "at most %d items are allowed, got %d", maxItems, count)
+	}
+}
+
+// typed checks that v has the JSON type implied by ty.
+func (c *conformanceChecker) typed(path AttributePath, ty cty.Type, v interface{}) {
+	if c.skip(path, v) || ty == cty.DynamicPseudoType {
+		return
+	}
+
+	switch {
+	case ty == cty.String:
+		if _, ok := v.(string); !ok {
+			c.wrongType(path, "string", v)
+		}
+	case ty == cty.Number:
+		if jsonTypeName(v) != "number" {
+			c.wrongType(path, "number", v)
+		}
+	case ty == cty.Bool:
+		if _, ok := v.(bool); !ok {
+			c.wrongType(path, "bool", v)
+		}
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		list, ok := v.([]interface{})
+		if !ok {
+			c.wrongType(path, "array", v)
+			return
+		}
+		if ty.IsTupleType() && len(list) != ty.Length() {
+			c.report(path, ConformanceWrongType, "expected %d elements, got %d", ty.Length(), len(list))
+			return
+		}
+		for i, ev := range list {
+			var ety cty.Type
+			if ty.IsTupleType() {
+				ety = ty.TupleElementType(i)
+			} else {
+				ety = ty.ElementType()
+			}
+			c.typed(path.child(IndexStep(i)), ety, ev)
+		}
+	case ty.IsMapType():
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			c.wrongType(path, "object", v)
+			return
+		}
+		for _, key := range mergedKeys(obj, nil) {
+			c.typed(path.child(KeyStep(key)), ty.ElementType(), obj[key])
+		}
+	case ty.IsObjectType():
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			c.wrongType(path, "object", v)
+			return
+		}
+		for _, name := range mergedKeys(obj, nil) {
+			if !ty.HasAttribute(name) {
+				c.report(path.child(AttributeStep(name)), ConformanceUnknownAttribute, "unsupported attribute %q", name)
+				continue
+			}
+			c.typed(path.child(AttributeStep(name)), ty.AttributeType(name), obj[name])
+		}
+	}
+}
+
+func (c *conformanceChecker) wrongType(path AttributePath, want string, v interface{}) {
+	c.report(path, ConformanceWrongType, "expected %s, got %s", want, jsonTypeName(v))
+}
+
+// objectNames returns the sorted union of the keys of obj and the
+// names declared in the schema.
+func objectNames(obj map[string]interface{}, attrs map[string]*SchemaAttribute, blocks map[string]*SchemaBlockType) []string {
+	seen := make(map[string]bool, len(obj)+len(attrs)+len(blocks))
+	names := make([]string, 0, len(seen))
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for name := range obj {
+		add(name)
+	}
+	for name := range attrs {
+		add(name)
+	}
+	for name := range blocks {
+		add(name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isEmptyContainer returns true if v is an empty object or array.
+func isEmptyContainer(v interface{}) bool {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// jsonTypeName returns the name of the JSON type of a decoded value.
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "string"
+	case bool:
+		return "bool"
+	case float64, json.Number, *big.Float, int, int64:
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
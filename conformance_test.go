@@ -0,0 +1,174 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testConformanceSchemas() *ProviderSchemas {
+	return testProviderSchemas("registry.terraform.io/hashicorp/test", map[string]*Schema{
+		"test_instance": {
+			Version: 1,
+			Block: &SchemaBlock{
+				Attributes: map[string]*SchemaAttribute{
+					"id":     {AttributeType: cty.String, Computed: true},
+					"ami":    {AttributeType: cty.String, Required: true},
+					"count":  {AttributeType: cty.Number, Optional: true},
+					"legacy": {AttributeType: cty.Bool, Optional: true, Deprecated: true},
+					"arn":    {AttributeType: cty.String, Computed: true, Deprecated: true},
+					"tags":   {AttributeType: cty.Map(cty.String), Optional: true},
+					"ports": {
+						AttributeNestedType: &SchemaNestedAttributeType{
+							NestingMode: SchemaNestingModeList,
+							MaxItems:    1,
+							Attributes: map[string]*SchemaAttribute{
+								"number": {AttributeType: cty.Number, Required: true},
+							},
+						},
+						Optional: true,
+					},
+				},
+				NestedBlocks: map[string]*SchemaBlockType{
+					"disk": {
+						NestingMode: SchemaNestingModeList,
+						MinItems:    1,
+						Block: &SchemaBlock{
+							Attributes: map[string]*SchemaAttribute{
+								"size": {AttributeType: cty.Number, Required: true},
+							},
+						},
+					},
+					"old": {
+						NestingMode: SchemaNestingModeSet,
+						Block:       &SchemaBlock{Deprecated: true},
+					},
+				},
+			},
+		},
+	})
+}
+
+func TestProviderSchemasCheckConformance(t *testing.T) {
+	r := &StateResource{
+		Address:       "test_instance.foo",
+		Mode:          ManagedResourceMode,
+		Type:          "test_instance",
+		ProviderName:  "registry.terraform.io/hashicorp/test",
+		SchemaVersion: 1,
+		AttributeValues: map[string]interface{}{
+			"id":     "i-1",
+			"arn":    "arn:test",
+			"ami":    nil,
+			"count":  "three",
+			"legacy": true,
+			"tags":   map[string]interface{}{"a": float64(1)},
+			"ports": []interface{}{
+				map[string]interface{}{"number": float64(80)},
+				map[string]interface{}{"extra": true},
+			},
+			"disk":  []interface{}{},
+			"old":   []interface{}{map[string]interface{}{}},
+			"bogus": "x",
+		},
+	}
+
+	type entry struct {
+		Path string
+		Kind ConformanceErrorKind
+	}
+	var actual []entry
+	for _, err := range testConformanceSchemas().CheckConformance(r, nil) {
+		if err.Address != r.Address {
+			t.Fatalf("unexpected address %q", err.Address)
+		}
+		actual = append(actual, entry{err.Path.String(), err.Kind})
+	}
+
+	expected := []entry{
+		{"ami", ConformanceMissingRequired},
+		{"bogus", ConformanceUnknownAttribute},
+		{"count", ConformanceWrongType},
+		{"disk", ConformanceTooFewItems},
+		{"legacy", ConformanceDeprecated},
+		{"old", ConformanceDeprecated},
+		{"ports[1].extra", ConformanceUnknownAttribute},
+		{"ports[1].number", ConformanceMissingRequired},
+		{"ports", ConformanceTooManyItems},
+		{`tags["a"]`, ConformanceWrongType},
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected errors (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestProviderSchemasCheckConformance_unknown(t *testing.T) {
+	r := &StateResource{
+		Address:       "test_instance.foo",
+		Mode:          ManagedResourceMode,
+		Type:          "test_instance",
+		ProviderName:  "hashicorp/test",
+		SchemaVersion: 1,
+		AttributeValues: map[string]interface{}{
+			"disk": []interface{}{map[string]interface{}{}},
+		},
+	}
+	unknown := map[string]interface{}{
+		"ami":  true,
+		"disk": []interface{}{map[string]interface{}{"size": true}},
+	}
+
+	if errs := testConformanceSchemas().CheckConformance(r, unknown); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestProviderSchemasCheckConformance_schemaErrors(t *testing.T) {
+	schemas := testConformanceSchemas()
+
+	r := &StateResource{
+		Address:         "test_instance.foo",
+		Mode:            ManagedResourceMode,
+		Type:            "test_instance",
+		ProviderName:    "hashicorp/test",
+		SchemaVersion:   0,
+		AttributeValues: map[string]interface{}{"ami": "ami-1", "disk": []interface{}{map[string]interface{}{"size": float64(1)}}},
+	}
+	errs := schemas.CheckConformance(r, nil)
+	if len(errs) != 1 || errs[0].Kind != ConformanceSchemaVersion {
+		t.Fatalf("expected a single schema version error, got %v", errs)
+	}
+	var versionErr *SchemaVersionMismatchError
+	if !errors.As(errs[0], &versionErr) {
+		t.Fatalf("expected error to wrap SchemaVersionMismatchError, got %#v", errs[0].Err)
+	}
+
+	r.Type = "test_missing"
+	errs = schemas.CheckConformance(r, nil)
+	if len(errs) != 1 || errs[0].Kind != ConformanceSchemaNotFound {
+		t.Fatalf("expected a single schema not found error, got %v", errs)
+	}
+	var notFound *ResourceSchemaNotFoundError
+	if !errors.As(errs[0], &notFound) {
+		t.Fatalf("expected error to wrap ResourceSchemaNotFoundError, got %#v", errs[0].Err)
+	}
+}
+
+func TestPlanCheckConformance_fixtures(t *testing.T) {
+	for _, fixture := range []string{"basic", "110_basic", "120_basic", "has_checks"} {
+		t.Run(fixture, func(t *testing.T) {
+			schemas := testLoadSchemas(t, fixture)
+			plan := testLoadPlan(t, fixture)
+
+			if errs := plan.CheckConformance(schemas); len(errs) != 0 {
+				t.Fatalf("expected no errors, got %v", errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// NumberMode selects how numbers are decoded in values that this package
+// does not type, such as resource attribute values, outputs, variables
+// and expression constants.
+type NumberMode int
+
+const (
+	// NumberFloat64 decodes numbers as float64, the default behavior of
+	// encoding/json. Integers larger than 2^53 and high-precision
+	// decimals lose precision.
+	NumberFloat64 NumberMode = iota
+
+	// NumberJSONNumber decodes numbers as json.Number, which keeps the
+	// number exactly as it appears in the JSON document.
+	NumberJSONNumber
+
+	// NumberBigFloat decodes numbers as *big.Float with the 512 bits of
+	// precision that cty uses for numbers, so that they can be used in
+	// arithmetic without parsing. Such numbers are marshaled back as
+	// the literal they were decoded from, whether the whole Plan, State
+	// or Config or only a part of it is marshaled. Numbers that were
+	// changed or created since are written the way Terraform writes
+	// numbers.
+	NumberBigFloat
+)
+
+// DecodeOptions controls how a Plan, State or Config is decoded. The
+// options apply to every section of the document, including the prior
+// state and configuration of a plan and the expressions of a
+// configuration, which are decoded by their own unmarshalers.
+type DecodeOptions struct {
+	// Numbers selects how numbers in untyped values are decoded.
+	Numbers NumberMode
+
+	// DisallowUnknownFields causes decoding to fail when the document
+	// contains an object key that does not match a field, as
+	// json.Decoder.DisallowUnknownFields does.
+	DisallowUnknownFields bool
+}
+
+// decode decodes b into v according to the options.
+func (o DecodeOptions) decode(b []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	if o.Numbers != NumberFloat64 {
+		dec.UseNumber()
+	}
+	if o.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	return dec.Decode(v)
+}
+
+// isJSONNull returns true if b is absent or the JSON null literal.
+func isJSONNull(b json.RawMessage) bool {
+	b = bytes.TrimSpace(b)
+	return len(b) == 0 || bytes.Equal(b, []byte("null"))
+}
+
+// expressionDecoder applies decode options to the expressions of a
+// configuration. Expression.UnmarshalJSON receives no options, so the
+// expressions are decoded once with the defaults, then revisited using
+// a tree of the configuration decoded with json.Number.
+type expressionDecoder struct {
+	opts DecodeOptions
+}
+
+// decodeExpressions applies the decode options of the configuration to
+// its expressions, given the JSON document it was decoded from.
+func (c *Config) decodeExpressions(b []byte) error {
+	if c.decodeOptions == (DecodeOptions{}) {
+		return nil
+	}
+
+	var tree map[string]interface{}
+	if err := (DecodeOptions{Numbers: NumberJSONNumber}).decode(b, &tree); err != nil {
+		return err
+	}
+
+	d := expressionDecoder{opts: c.decodeOptions}
+	providers := jsonObject(tree["provider_config"])
+	for key, pc := range c.ProviderConfigs {
+		if pc == nil {
+			continue
+		}
+		if err := d.expressions(pc.Expressions, jsonObject(jsonObject(providers[key])["expressions"])); err != nil {
+			return err
+		}
+	}
+	return d.module(c.RootModule, jsonObject(tree["root_module"]))
+}
+
+func (d expressionDecoder) module(m *ConfigModule, tree map[string]interface{}) error {
+	if m == nil {
+		return nil
+	}
+
+	outputs := jsonObject(tree["outputs"])
+	for name, o := range m.Outputs {
+		if o == nil {
+			continue
+		}
+		if err := d.expression(o.Expression, jsonObject(outputs[name])["expression"]); err != nil {
+			return err
+		}
+	}
+
+	resources, _ := tree["resources"].([]interface{})
+	for i, r := range m.Resources {
+		if r == nil || i >= len(resources) {
+			continue
+		}
+		rt := jsonObject(resources[i])
+		if err := d.expressions(r.Expressions, jsonObject(rt["expressions"])); err != nil {
+			return err
+		}
+		if err := d.expression(r.CountExpression, rt["count_expression"]); err != nil {
+			return err
+		}
+		if err := d.expression(r.ForEachExpression, rt["for_each_expression"]); err != nil {
+			return err
+		}
+
+		provisioners, _ := rt["provisioners"].([]interface{})
+		for j, p := range r.Provisioners {
+			if p == nil || j >= len(provisioners) {
+				continue
+			}
+			if err := d.expressions(p.Expressions, jsonObject(jsonObject(provisioners[j])["expressions"])); err != nil {
+				return err
+			}
+		}
+	}
+
+	calls := jsonObject(tree["module_calls"])
+	for name, mc := range m.ModuleCalls {
+		if mc == nil {
+			continue
+		}
+		ct := jsonObject(calls[name])
+		if err := d.expressions(mc.Expressions, jsonObject(ct["expressions"])); err != nil {
+			return err
+		}
+		if err := d.expression(mc.CountExpression, ct["count_expression"]); err != nil {
+			return err
+		}
+		if err := d.expression(mc.ForEachExpression, ct["for_each_expression"]); err != nil {
+			return err
+		}
+		if err := d.module(mc.Module, jsonObject(ct["module"])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d expressionDecoder) expressions(exprs map[string]*Expression, tree map[string]interface{}) error {
+	for key, e := range exprs {
+		if err := d.expression(e, tree[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d expressionDecoder) expression(e *Expression, tree interface{}) error {
+	if e == nil || e.ExpressionData == nil {
+		return nil
+	}
+
+	if blocks, ok := tree.([]interface{}); ok {
+		for i, block := range e.NestedBlocks {
+			if i >= len(blocks) {
+				break
+			}
+			if err := d.expressions(block, jsonObject(blocks[i])); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj := jsonObject(tree)
+	if d.opts.DisallowUnknownFields {
+		if err := d.check(obj); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := obj["constant_value"]; ok && e.ConstantValue != UnknownConstantValue && d.opts.Numbers != NumberFloat64 {
+		e.ConstantValue = v
+	}
+	return nil
+}
+
+// check reports unknown fields in the tree of an expression. Single,
+// group and map nested blocks are objects of expressions keyed by
+// attribute name rather than arrays, and are not kept by Expression,
+// so they are only checked here.
+func (d expressionDecoder) check(tree interface{}) error {
+	switch v := tree.(type) {
+	case []interface{}:
+		for _, block := range v {
+			if err := d.check(jsonObject(block)); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		unknown := ""
+		nested := true
+		for key, value := range v {
+			keys = append(keys, key)
+			if key != "constant_value" && key != "references" && (unknown == "" || key < unknown) {
+				unknown = key
+			}
+			switch value.(type) {
+			case map[string]interface{}, []interface{}:
+			default:
+				nested = false
+			}
+		}
+		if unknown == "" {
+			return nil
+		}
+		if !nested {
+			return fmt.Errorf("json: unknown field %q", unknown)
+		}
+
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := d.check(v[key]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonObject returns v as a JSON object, or nil if it is not one.
+func jsonObject(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
@@ -0,0 +1,201 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const testDecodePlan = `{
+  "format_version": "1.2",
+  "planned_values": {
+    "outputs": {"id": {"sensitive": false, "value": 9007199254740993}},
+    "root_module": {}
+  },
+  "prior_state": {
+    "format_version": "1.0",
+    "values": {
+      "outputs": {"id": {"sensitive": false, "value": 9007199254740995}},
+      "root_module": {
+        "resources": [{
+          "address": "example_resource.test",
+          "mode": "managed",
+          "type": "example_resource",
+          "name": "test",
+          "provider_name": "registry.terraform.io/hashicorp/example",
+          "schema_version": 0,
+          "values": {"id": 9007199254740997}
+        }]
+      }
+    }
+  },
+  "configuration": {
+    "provider_config": {
+      "example": {
+        "name": "example",
+        "expressions": {"account": {"constant_value": 123456789012345678}}
+      }
+    },
+    "root_module": {
+      "resources": [{
+        "address": "example_resource.test",
+        "mode": "managed",
+        "type": "example_resource",
+        "name": "test",
+        "provider_config_key": "example",
+        "expressions": {
+          "id": {"constant_value": 9007199254740999},
+          "block": [{"size": {"constant_value": 0.1000000000000000055511151231257827}}],
+          "timeouts": {"create": {"constant_value": "5m"}}
+        },
+        "schema_version": 0
+      }],
+      "module_calls": {
+        "child": {
+          "source": "./child",
+          "count_expression": {"constant_value": 9007199254741001},
+          "module": {
+            "outputs": {"out": {"expression": {"constant_value": 9007199254741003}}}
+          }
+        }
+      },
+      "variables": {"big": {"default": 9007199254741005}}
+    }
+  }
+}`
+
+// testDecodeNumbers returns the numbers of testDecodePlan from every
+// section of the plan.
+func testDecodeNumbers(plan *Plan) map[string]interface{} {
+	root := plan.Config.RootModule
+	child := root.ModuleCalls["child"]
+	return map[string]interface{}{
+		"planned output":    plan.PlannedValues.Outputs["id"].Value,
+		"prior output":      plan.PriorState.Values.Outputs["id"].Value,
+		"prior resource":    plan.PriorState.Values.RootModule.Resources[0].AttributeValues["id"],
+		"provider":          plan.Config.ProviderConfigs["example"].Expressions["account"].ConstantValue,
+		"resource":          root.Resources[0].Expressions["id"].ConstantValue,
+		"nested block":      root.Resources[0].Expressions["block"].NestedBlocks[0]["size"].ConstantValue,
+		"count":             child.CountExpression.ConstantValue,
+		"module output":     child.Module.Outputs["out"].Expression.ConstantValue,
+		"variable defaults": root.Variables["big"].Default,
+	}
+}
+
+func TestPlanDecodeOptions_numbers(t *testing.T) {
+	plan := &Plan{}
+	plan.UseJSONNumber(true)
+	if err := json.Unmarshal([]byte(testDecodePlan), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"planned output":    json.Number("9007199254740993"),
+		"prior output":      json.Number("9007199254740995"),
+		"prior resource":    json.Number("9007199254740997"),
+		"provider":          json.Number("123456789012345678"),
+		"resource":          json.Number("9007199254740999"),
+		"nested block":      json.Number("0.1000000000000000055511151231257827"),
+		"count":             json.Number("9007199254741001"),
+		"module output":     json.Number("9007199254741003"),
+		"variable defaults": json.Number("9007199254741005"),
+	}
+	if diff := cmp.Diff(expected, testDecodeNumbers(plan)); diff != "" {
+		t.Fatalf("unexpected numbers (-expected +actual):\n%s", diff)
+	}
+}
+
+func TestPlanDecodeOptions_float64(t *testing.T) {
+	plan := &Plan{}
+	if err := json.Unmarshal([]byte(testDecodePlan), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, v := range testDecodeNumbers(plan) {
+		if _, ok := v.(float64); !ok {
+			t.Errorf("%s: expected float64, got %T", name, v)
+		}
+	}
+}
+
+func TestDecodeOptions_disallowUnknownFields(t *testing.T) {
+	strict := DecodeOptions{DisallowUnknownFields: true}
+
+	plan := &Plan{}
+	plan.SetDecodeOptions(strict)
+	if err := json.Unmarshal([]byte(testDecodePlan), plan); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		old, new string
+	}{
+		"prior state":   {`"schema_version": 0,`, `"schema_version": 0, "bogus": true,`},
+		"configuration": {`"provider_config_key": "example",`, `"provider_config_key": "example", "bogus": true,`},
+		"expression":    {`"constant_value": 9007199254740999`, `"constant_value": 9007199254740999, "bogus": true`},
+		"nested block":  {`"constant_value": "5m"`, `"constant_value": "5m", "bogus": true`},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			doc := strings.Replace(testDecodePlan, tc.old, tc.new, 1)
+
+			if err := json.Unmarshal([]byte(doc), &Plan{}); err != nil {
+				t.Fatalf("expected lenient decoding to succeed, got %s", err)
+			}
+
+			plan := &Plan{}
+			plan.SetDecodeOptions(strict)
+			err := json.Unmarshal([]byte(doc), plan)
+			if err == nil || !strings.Contains(err.Error(), `"bogus"`) {
+				t.Fatalf("expected unknown field error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecodeOptions_fixtures(t *testing.T) {
+	opts := DecodeOptions{Numbers: NumberJSONNumber, DisallowUnknownFields: true}
+
+	plans, err := filepath.Glob("testdata/*/plan.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range plans {
+		t.Run(path, func(t *testing.T) {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			plan := &Plan{}
+			plan.SetDecodeOptions(opts)
+			if err := json.Unmarshal(b, plan); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+
+	states, err := filepath.Glob("testdata/*/state.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range states {
+		t.Run(path, func(t *testing.T) {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			state := &State{}
+			state.SetDecodeOptions(opts)
+			if err := json.Unmarshal(b, state); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import "sort"
+
+// AttributeAction describes how a single value changes between the
+// Before and After of a Change.
+type AttributeAction string
+
+const (
+	// AttributeActionNone indicates the value does not change. Only
+	// values whose sensitivity changes are reported with this action.
+	AttributeActionNone AttributeAction = "none"
+
+	// AttributeActionAdd indicates the value is absent or null in
+	// Before and set in After.
+	AttributeActionAdd AttributeAction = "add"
+
+	// AttributeActionRemove indicates the value is set in Before and
+	// absent or null in After.
+	AttributeActionRemove AttributeAction = "remove"
+
+	// AttributeActionModify indicates the value is set on both sides
+	// but differs.
+	AttributeActionModify AttributeAction = "modify"
+
+	// AttributeActionUnknown indicates the value will only be known
+	// after apply, as marked in AfterUnknown.
+	AttributeActionUnknown AttributeAction = "unknown"
+)
+
+// AttributeChange describes the change of a single value within a
+// Change.
+type AttributeChange struct {
+	// The path of the value within the object.
+	Path AttributePath
+
+	// The action taken on the value.
+	Action AttributeAction
+
+	// The values before and after the change. Either is nil if absent
+	// or null, and After is always nil for AttributeActionUnknown.
+	//
+	// Values are not redacted: check BeforeSensitive and
+	// AfterSensitive before displaying them.
+	Before interface{}
+	After  interface{}
+
+	// BeforeSensitive and AfterSensitive report whether the value is
+	// marked as sensitive on either side of the change.
+	BeforeSensitive bool
+	AfterSensitive  bool
+
+	// ForcesReplacement is true if the change to this value is one of
+	// the reasons the whole object is replaced, as listed in
+	// Change.ReplacePaths.
+	ForcesReplacement bool
+}
+
+// SensitivityChanged returns true if the value is sensitive on only
+// one side of the change.
+func (ac *AttributeChange) SensitivityChanged() bool {
+	return ac.BeforeSensitive != ac.AfterSensitive
+}
+
+// Diff computes the attribute-level changes between Before and After,
+// including values that become unknown and values whose sensitivity
+// changes. Objects and lists are descended into, so only the deepest
+// changed values are reported. Changes are sorted by path.
+func (c *Change) Diff() []*AttributeChange {
+	return c.DiffWithSchema(nil)
+}
+
+// DiffWithSchema is like Diff, using the schema of the changed object
+// to compare the collections it types as sets regardless of the order
+// of their elements. Set elements are not paired with each other, so
+// an element found on only one side is reported whole, as added at its
+// index in After or removed at its index in Before. A nil schema
+// compares every collection in order, as Diff does.
+func (c *Change) DiffWithSchema(schema *Schema) []*AttributeChange {
+	if c == nil {
+		return nil
+	}
+
+	unknown := maskPaths(c.AfterUnknown)
+	replace := c.replacePaths()
+
+	var changes []*AttributeChange
+	changed := make(map[string]bool)
+	add := func(ac *AttributeChange) {
+		ac.BeforeSensitive = maskAtPath(c.BeforeSensitive, ac.Path)
+		ac.AfterSensitive = maskAtPath(c.AfterSensitive, ac.Path)
+		for _, rp := range replace {
+			if ac.Path.HasPrefix(rp) || rp.HasPrefix(ac.Path) {
+				ac.ForcesReplacement = true
+				break
+			}
+		}
+		changes = append(changes, ac)
+		changed[ac.Path.String()] = true
+	}
+
+	for _, path := range unknown {
+		before, _ := valueAtPath(c.Before, path)
+		add(&AttributeChange{Path: path, Action: AttributeActionUnknown, Before: before})
+	}
+
+	walkValueDiff(nil, schemaType(schema), c.Before, c.After, func(path AttributePath, before, after interface{}) {
+		for _, u := range unknown {
+			if path.HasPrefix(u) {
+				return
+			}
+		}
+
+		ac := &AttributeChange{Path: path, Before: before, After: after}
+		switch {
+		case before == nil:
+			ac.Action = AttributeActionAdd
+		case after == nil:
+			ac.Action = AttributeActionRemove
+		default:
+			ac.Action = AttributeActionModify
+		}
+		add(ac)
+	})
+
+	for _, path := range append(maskPaths(c.BeforeSensitive), maskPaths(c.AfterSensitive)...) {
+		if changed[path.String()] {
+			continue
+		}
+		if maskAtPath(c.BeforeSensitive, path) == maskAtPath(c.AfterSensitive, path) {
+			continue
+		}
+		before, _ := valueAtPath(c.Before, path)
+		after, _ := valueAtPath(c.After, path)
+		add(&AttributeChange{Path: path, Action: AttributeActionNone, Before: before, After: after})
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		return changes[i].Path.less(changes[j].Path)
+	})
+	return changes
+}
+
+// replacePaths returns the ReplacePaths of the change as typed paths,
+// skipping any malformed entry.
+func (c *Change) replacePaths() []AttributePath {
+	var ret []AttributePath
+	for _, rp := range c.ReplacePaths {
+		indexes, ok := rp.([]interface{})
+		if !ok {
+			continue
+		}
+		if path, err := AttributePathFromIndexes(indexes); err == nil {
+			ret = append(ret, path)
+		}
+	}
+	return ret
+}
+
+// maskPaths returns the paths of all the values marked in a mask tree
+// such as Change.AfterUnknown, in a stable order.
+func maskPaths(mask interface{}) []AttributePath {
+	var ret []AttributePath
+	var walk func(path AttributePath, mask interface{})
+	walk = func(path AttributePath, mask interface{}) {
+		switch mask := mask.(type) {
+		case bool:
+			if mask {
+				ret = append(ret, path)
+			}
+		case map[string]interface{}:
+			for _, key := range mergedKeys(mask, nil) {
+				walk(path.child(AttributeStep(key)), mask[key])
+			}
+		case []interface{}:
+			for i, v := range mask {
+				walk(path.child(IndexStep(i)), v)
+			}
+		}
+	}
+	walk(nil, mask)
+	return ret
+}
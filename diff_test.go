@@ -0,0 +1,114 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestChangeDiff(t *testing.T) {
+	change := &Change{
+		Actions: Actions{ActionDelete, ActionCreate},
+		Before: map[string]interface{}{
+			"id":       "i-1",
+			"ami":      "ami-1",
+			"name":     "web",
+			"removed":  "x",
+			"password": "secret",
+			"token":    "abc",
+			"disks": []interface{}{
+				map[string]interface{}{"size": float64(10)},
+			},
+		},
+		After: map[string]interface{}{
+			"ami":      "ami-2",
+			"name":     "web",
+			"added":    "y",
+			"password": "secret",
+			"token":    "abc",
+			"disks": []interface{}{
+				map[string]interface{}{"size": float64(20)},
+				map[string]interface{}{"size": float64(30)},
+			},
+		},
+		AfterUnknown:    map[string]interface{}{"id": true, "arn": true},
+		BeforeSensitive: map[string]interface{}{"password": true},
+		AfterSensitive:  map[string]interface{}{"password": true, "token": true},
+		ReplacePaths:    []interface{}{[]interface{}{"ami"}, []interface{}{"disks", float64(0), "size"}},
+	}
+
+	type entry struct {
+		Path            string
+		Action          AttributeAction
+		Before, After   interface{}
+		BeforeSensitive bool
+		AfterSensitive  bool
+		Replace         bool
+	}
+
+	var actual []entry
+	for _, ac := range change.Diff() {
+		actual = append(actual, entry{ac.Path.String(), ac.Action, ac.Before, ac.After, ac.BeforeSensitive, ac.AfterSensitive, ac.ForcesReplacement})
+	}
+
+	expected := []entry{
+		{"added", AttributeActionAdd, nil, "y", false, false, false},
+		{"ami", AttributeActionModify, "ami-1", "ami-2", false, false, true},
+		{"arn", AttributeActionUnknown, nil, nil, false, false, false},
+		{"disks[0].size", AttributeActionModify, float64(10), float64(20), false, false, true},
+		{"disks[1].size", AttributeActionAdd, nil, float64(30), false, false, false},
+		{"id", AttributeActionUnknown, "i-1", nil, false, false, false},
+		{"removed", AttributeActionRemove, "x", nil, false, false, false},
+		{"token", AttributeActionNone, "abc", "abc", false, true, false},
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+}
+
+func TestChangeDiff_create(t *testing.T) {
+	change := &Change{
+		Actions:      Actions{ActionCreate},
+		After:        map[string]interface{}{"ami": "boop", "tags": map[string]interface{}{"a": "b"}},
+		AfterUnknown: map[string]interface{}{"id": true},
+	}
+
+	var actual []string
+	for _, ac := range change.Diff() {
+		actual = append(actual, string(ac.Action)+" "+ac.Path.String())
+	}
+
+	expected := []string{"add ami", "unknown id", "add tags.a"}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+}
+
+func TestChangeDiff_order(t *testing.T) {
+	before := make([]interface{}, 12)
+	after := make([]interface{}, 12)
+	var expected []string
+	for i := range before {
+		before[i] = float64(i)
+		after[i] = float64(i + 1)
+		expected = append(expected, fmt.Sprintf("modify l[%d]", i))
+	}
+	expected = append(expected, "add m.a")
+	change := &Change{
+		Actions: Actions{ActionUpdate},
+		Before:  map[string]interface{}{"l": before},
+		After:   map[string]interface{}{"l": after, "m": map[string]interface{}{"a": "b"}},
+	}
+
+	var actual []string
+	for _, ac := range change.Diff() {
+		actual = append(actual, string(ac.Action)+" "+ac.Path.String())
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected diff: %s", diff)
+	}
+}
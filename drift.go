@@ -0,0 +1,222 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// DriftOutcome describes what the planned changes will do to a value
+// that was changed outside of Terraform.
+type DriftOutcome string
+
+const (
+	// DriftOutcomeReverted indicates that the planned change restores
+	// the value recorded before the drift.
+	DriftOutcomeReverted DriftOutcome = "reverted"
+
+	// DriftOutcomeOverwritten indicates that the planned change sets
+	// the value to something other than both the drifted value and the
+	// value recorded before the drift.
+	DriftOutcomeOverwritten DriftOutcome = "overwritten"
+
+	// DriftOutcomeKept indicates that the drifted value is kept by the
+	// plan, either because no change is planned or because the planned
+	// value matches it.
+	DriftOutcomeKept DriftOutcome = "kept"
+
+	// DriftOutcomeUnknown indicates that the planned value will only be
+	// known after apply.
+	DriftOutcomeUnknown DriftOutcome = "unknown"
+
+	// DriftOutcomeDestroyed indicates that the drifted object will be
+	// destroyed by the plan.
+	DriftOutcomeDestroyed DriftOutcome = "destroyed"
+)
+
+// DriftReport describes the changes made outside of Terraform, as
+// recorded in Plan.ResourceDrift, along with what the planned changes
+// will do to them.
+type DriftReport struct {
+	// Resources lists the drifted resource instances, in the order
+	// they appear in Plan.ResourceDrift.
+	Resources []*DriftedResource
+}
+
+// DriftedResource describes a single resource instance that was
+// changed outside of Terraform.
+type DriftedResource struct {
+	// The absolute address of the resource instance.
+	Address string
+
+	// The deposed key of the object, if it is a deposed object.
+	DeposedKey string
+
+	// The entry of Plan.ResourceDrift for this object.
+	Drift *ResourceChange
+
+	// The entry of Plan.ResourceChanges for this object, or nil if
+	// there is none.
+	Change *ResourceChange
+
+	// Deleted is true if the object was deleted outside of Terraform.
+	// Attributes is then empty and Outcome describes whether the plan
+	// recreates the object.
+	Deleted bool
+
+	// Outcome summarizes what the plan does to the object as a whole
+	// when it is deleted or destroyed. It is empty otherwise, in which
+	// case the outcome of each drifted attribute is reported in
+	// Attributes.
+	Outcome DriftOutcome
+
+	// Attributes lists the values that changed outside of Terraform,
+	// sorted by path.
+	Attributes []*DriftedAttribute
+}
+
+// DriftedAttribute describes a single value that changed outside of
+// Terraform.
+type DriftedAttribute struct {
+	// The path of the value within the resource.
+	Path AttributePath
+
+	// Before is the value recorded before the drift, and After the
+	// value found when refreshing. Either is nil if absent, and both
+	// are nil if Sensitive is true.
+	Before interface{}
+	After  interface{}
+
+	// Sensitive is true if either value is marked as sensitive, in
+	// which case Before and After are redacted.
+	Sensitive bool
+
+	// Outcome describes what the planned change does to the value.
+	Outcome DriftOutcome
+}
+
+// DriftReport analyzes the ResourceDrift of the plan against its
+// ResourceChanges.
+func (p *Plan) DriftReport() *DriftReport {
+	return p.DriftReportWithSchemas(nil)
+}
+
+// DriftReportWithSchemas is like DriftReport, using the schemas of the
+// drifted resources to compare the collections they type as sets
+// regardless of the order of their elements, as Change.DiffWithSchema
+// does. Resources without a schema in schemas are compared in order.
+func (p *Plan) DriftReportWithSchemas(schemas *ProviderSchemas) *DriftReport {
+	report := &DriftReport{}
+	if p == nil {
+		return report
+	}
+
+	idx := p.Index()
+	for _, drift := range p.ResourceDrift {
+		if drift == nil || drift.Change == nil {
+			continue
+		}
+		rc := idx.ResourceChange(indexKey(drift.Address, drift.DeposedKey))
+		var schema *Schema
+		if schemas != nil {
+			schema, _ = schemas.ResourceChangeSchema(drift)
+		}
+		report.Resources = append(report.Resources, newDriftedResource(drift, rc, schemaType(schema)))
+	}
+
+	return report
+}
+
+func newDriftedResource(drift, rc *ResourceChange, ty cty.Type) *DriftedResource {
+	r := &DriftedResource{
+		Address:    drift.Address,
+		DeposedKey: drift.DeposedKey,
+		Drift:      drift,
+		Change:     rc,
+		Deleted:    drift.Change.Actions.Delete() || (drift.Change.Before != nil && drift.Change.After == nil),
+	}
+
+	var planned *Change
+	if rc != nil {
+		planned = rc.Change
+	}
+
+	switch {
+	case planned != nil && planned.Actions.Delete():
+		r.Outcome = DriftOutcomeDestroyed
+	case r.Deleted:
+		r.Outcome = DriftOutcomeKept
+		if planned != nil && (planned.Actions.Create() || planned.Actions.Replace()) {
+			r.Outcome = DriftOutcomeReverted
+		}
+	}
+
+	if r.Deleted {
+		return r
+	}
+
+	walkValueDiff(nil, ty, drift.Change.Before, drift.Change.After, func(path AttributePath, before, after interface{}) {
+		attr := &DriftedAttribute{
+			Path:      path,
+			Before:    before,
+			After:     after,
+			Sensitive: maskAtPath(drift.Change.BeforeSensitive, path) || maskAtPath(drift.Change.AfterSensitive, path),
+			Outcome:   driftOutcome(path, ty, before, after, planned),
+		}
+		if attr.Sensitive {
+			attr.Before, attr.After = nil, nil
+		}
+		r.Attributes = append(r.Attributes, attr)
+	})
+	sort.SliceStable(r.Attributes, func(i, j int) bool {
+		return r.Attributes[i].Path.less(r.Attributes[j].Path)
+	})
+
+	return r
+}
+
+// driftOutcome returns the outcome of the drift of the value at path
+// within an object of type ty.
+func driftOutcome(path AttributePath, ty cty.Type, before, after interface{}, planned *Change) DriftOutcome {
+	if planned == nil || planned.Actions.NoOp() || planned.Actions.Read() {
+		return DriftOutcomeKept
+	}
+	if planned.Actions.Delete() {
+		return DriftOutcomeDestroyed
+	}
+	if maskAtPath(planned.AfterUnknown, path) {
+		return DriftOutcomeUnknown
+	}
+
+	// Set elements are reported whole and have no stable index, so
+	// they are looked up in the planned set instead.
+	if len(path) > 0 {
+		parent := path[:len(path)-1]
+		if pty := typeAtPath(ty, parent); pty.IsSetType() {
+			set, _ := valueAtPath(planned.After, parent)
+			elems, _ := set.([]interface{})
+			afterPlanned := after != nil && setContains(pty.ElementType(), elems, after)
+			beforePlanned := before != nil && setContains(pty.ElementType(), elems, before)
+			switch {
+			case (after == nil || afterPlanned) && !beforePlanned:
+				return DriftOutcomeKept
+			case !afterPlanned && (before == nil || beforePlanned):
+				return DriftOutcomeReverted
+			}
+			return DriftOutcomeOverwritten
+		}
+	}
+
+	ty = typeAtPath(ty, path)
+	value, _ := valueAtPath(planned.After, path)
+	switch {
+	case valuesEqual(ty, value, after):
+		return DriftOutcomeKept
+	case valuesEqual(ty, value, before):
+		return DriftOutcomeReverted
+	}
+	return DriftOutcomeOverwritten
+}
@@ -0,0 +1,167 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// testDriftAttribute is the part of a DriftedAttribute that tests
+// compare.
+type testDriftAttribute struct {
+	Path      string
+	Before    interface{}
+	After     interface{}
+	Sensitive bool
+	Outcome   DriftOutcome
+}
+
+func testDriftAttributes(r *DriftedResource) []testDriftAttribute {
+	var ret []testDriftAttribute
+	for _, a := range r.Attributes {
+		ret = append(ret, testDriftAttribute{a.Path.String(), a.Before, a.After, a.Sensitive, a.Outcome})
+	}
+	return ret
+}
+
+func TestPlanDriftReport(t *testing.T) {
+	plan := &Plan{
+		ResourceDrift: []*ResourceChange{
+			{
+				Address: "aws_instance.web",
+				Change: &Change{
+					Actions: Actions{ActionUpdate},
+					Before: map[string]interface{}{
+						"instance_type": "t3.micro",
+						"tags":          map[string]interface{}{"env": "prod", "owner": "ops"},
+						"password":      "old",
+						"ami":           "ami-1",
+						"ports":         []interface{}{float64(80)},
+					},
+					After: map[string]interface{}{
+						"instance_type": "t3.large",
+						"tags":          map[string]interface{}{"env": "dev", "owner": "ops", "extra": "x"},
+						"password":      "new",
+						"ami":           "ami-2",
+						"ports":         []interface{}{float64(80), float64(443)},
+					},
+					BeforeSensitive: map[string]interface{}{"password": true},
+					AfterSensitive:  map[string]interface{}{"password": true},
+				},
+			},
+			{
+				Address: "aws_instance.gone",
+				Change: &Change{
+					Actions: Actions{ActionDelete},
+					Before:  map[string]interface{}{"id": "i-1"},
+				},
+			},
+			{
+				Address: "aws_instance.untouched",
+				Change: &Change{
+					Actions: Actions{ActionUpdate},
+					Before:  map[string]interface{}{"id": "i-2", "tag": "a"},
+					After:   map[string]interface{}{"id": "i-2", "tag": "b"},
+				},
+			},
+		},
+		ResourceChanges: []*ResourceChange{
+			{
+				Address: "aws_instance.web",
+				Change: &Change{
+					Actions: Actions{ActionUpdate},
+					After: map[string]interface{}{
+						"instance_type": "t3.micro",
+						"tags":          map[string]interface{}{"env": "staging", "owner": "ops"},
+						"password":      "old",
+						"ports":         []interface{}{float64(80), float64(443)},
+					},
+					AfterUnknown: map[string]interface{}{"ami": true},
+				},
+			},
+			{
+				Address: "aws_instance.gone",
+				Change:  &Change{Actions: Actions{ActionCreate}},
+			},
+		},
+	}
+
+	report := plan.DriftReport()
+	if len(report.Resources) != 3 {
+		t.Fatalf("expected 3 drifted resources, got %d", len(report.Resources))
+	}
+
+	web := report.Resources[0]
+	if web.Change != plan.ResourceChanges[0] || web.Deleted || web.Outcome != "" {
+		t.Fatalf("unexpected resource %#v", web)
+	}
+	expected := []testDriftAttribute{
+		{"ami", "ami-1", "ami-2", false, DriftOutcomeUnknown},
+		{"instance_type", "t3.micro", "t3.large", false, DriftOutcomeReverted},
+		{"password", nil, nil, true, DriftOutcomeReverted},
+		{"ports[1]", nil, float64(443), false, DriftOutcomeKept},
+		{"tags.env", "prod", "dev", false, DriftOutcomeOverwritten},
+		{"tags.extra", nil, "x", false, DriftOutcomeReverted},
+	}
+	if diff := cmp.Diff(expected, testDriftAttributes(web)); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+
+	gone := report.Resources[1]
+	if !gone.Deleted || gone.Outcome != DriftOutcomeReverted || len(gone.Attributes) != 0 {
+		t.Fatalf("unexpected deleted resource %#v", gone)
+	}
+
+	untouched := report.Resources[2]
+	expected = []testDriftAttribute{{"tag", "a", "b", false, DriftOutcomeKept}}
+	if diff := cmp.Diff(expected, testDriftAttributes(untouched)); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+}
+
+func TestPlanDriftReportWithSchemas(t *testing.T) {
+	schemas := testProviderSchemas("registry.terraform.io/hashicorp/aws", map[string]*Schema{
+		"aws_security_group": testSetSchema(),
+	})
+	plan := &Plan{
+		ResourceDrift: []*ResourceChange{
+			{
+				Address:      "aws_security_group.web",
+				Mode:         ManagedResourceMode,
+				Type:         "aws_security_group",
+				ProviderName: "registry.terraform.io/hashicorp/aws",
+				Change: &Change{
+					Actions: Actions{ActionUpdate},
+					Before:  map[string]interface{}{"cidr_blocks": []interface{}{"a", "b", "c"}},
+					After:   map[string]interface{}{"cidr_blocks": []interface{}{"c", "a", "d", "e"}},
+				},
+			},
+		},
+		ResourceChanges: []*ResourceChange{
+			{
+				Address: "aws_security_group.web",
+				Change: &Change{
+					Actions: Actions{ActionUpdate},
+					After:   map[string]interface{}{"cidr_blocks": []interface{}{"e", "b", "a", "c"}},
+				},
+			},
+		},
+	}
+
+	expected := []testDriftAttribute{
+		{"cidr_blocks[1]", "b", nil, false, DriftOutcomeReverted},
+		{"cidr_blocks[2]", nil, "d", false, DriftOutcomeReverted},
+		{"cidr_blocks[3]", nil, "e", false, DriftOutcomeKept},
+	}
+	actual := testDriftAttributes(plan.DriftReportWithSchemas(schemas).Resources[0])
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected attributes: %s", diff)
+	}
+
+	if n := len(plan.DriftReport().Resources[0].Attributes); n != 4 {
+		t.Fatalf("expected 4 attributes without schemas, got %d", n)
+	}
+}
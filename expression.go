@@ -107,7 +107,9 @@ func (e *Expression) MarshalJSON() ([]byte, error) {
 		})
 	}
 
-	return json.Marshal(e.ExpressionData)
+	data := *e.ExpressionData
+	data.ConstantValue = jsonNumbers(data.ConstantValue)
+	return json.Marshal(&data)
 }
 
 func marshalExpressionBlocks(nested []map[string]*Expression) ([]byte, error) {
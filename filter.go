@@ -0,0 +1,431 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ActionsPredicate reports whether a set of Actions is of interest.
+// The helpers of Actions can be used directly as predicates, ie:
+// Actions.Replace.
+type ActionsPredicate func(Actions) bool
+
+// ChangeFilter selects resource changes matching all of its criteria.
+// Criteria are added using its builder methods, and any criterion
+// given several values matches if any of them does. A new ChangeFilter
+// matches every change.
+//
+// For example, all managed "aws_iam_*" resources under module.prod
+// that are being replaced or deleted can be selected with:
+//
+//	NewChangeFilter().
+//		Mode(ManagedResourceMode).
+//		Type("aws_iam_*").
+//		Module("module.prod").
+//		Actions(Actions.Replace, Actions.Delete)
+//
+// The same filter can be parsed from an expression using
+// ParseChangeFilter.
+type ChangeFilter struct {
+	predicates []func(*ResourceChange) bool
+	err        error
+}
+
+// NewChangeFilter returns a ChangeFilter matching every change.
+func NewChangeFilter() *ChangeFilter {
+	return &ChangeFilter{}
+}
+
+func (f *ChangeFilter) add(pred func(*ResourceChange) bool) *ChangeFilter {
+	f.predicates = append(f.predicates, pred)
+	return f
+}
+
+func (f *ChangeFilter) fail(err error) *ChangeFilter {
+	if f.err == nil {
+		f.err = err
+	}
+	return f
+}
+
+// Err returns the first error encountered while building the filter,
+// such as an invalid pattern or address.
+func (f *ChangeFilter) Err() error {
+	return f.err
+}
+
+// Mode selects changes to resources of the supplied mode.
+func (f *ChangeFilter) Mode(mode ResourceMode) *ChangeFilter {
+	return f.add(func(rc *ResourceChange) bool {
+		return resourceModeOrManaged(rc.Mode) == mode
+	})
+}
+
+// Type selects changes to resources whose type matches any of the
+// supplied glob patterns, using the syntax of path.Match.
+func (f *ChangeFilter) Type(patterns ...string) *ChangeFilter {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return f.fail(fmt.Errorf("invalid type pattern %q: %w", pattern, err))
+		}
+	}
+	return f.add(func(rc *ResourceChange) bool {
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, rc.Type); ok {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Module selects changes to resources in any of the supplied module
+// instances or their descendants. A prefix step without an instance
+// key, ie: "module.prod", matches every instance of that module call.
+// The empty string selects changes in the root module only.
+func (f *ChangeFilter) Module(prefixes ...string) *ChangeFilter {
+	parsed := make([]ModuleInstancePath, len(prefixes))
+	for i, prefix := range prefixes {
+		p, err := ParseModuleInstancePath(prefix)
+		if err != nil {
+			return f.fail(err)
+		}
+		parsed[i] = p
+	}
+	return f.add(func(rc *ResourceChange) bool {
+		addr, err := rc.ParseAddress()
+		if err != nil {
+			return false
+		}
+		for _, prefix := range parsed {
+			if prefix.IsRoot() && addr.Module.IsRoot() {
+				return true
+			}
+			if !prefix.IsRoot() && moduleHasPrefix(addr.Module, prefix) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// moduleHasPrefix returns true if prefix is an ancestor of, or the same
+// module as, path. Steps of prefix without a key match any key.
+func moduleHasPrefix(path, prefix ModuleInstancePath) bool {
+	if len(prefix) > len(path) {
+		return false
+	}
+	for i, step := range prefix {
+		if step.Name != path[i].Name {
+			return false
+		}
+		if step.Key != NoKey && !instanceKeyEqual(step.Key, path[i].Key) {
+			return false
+		}
+	}
+	return true
+}
+
+// Actions selects changes whose actions satisfy any of the supplied
+// predicates.
+func (f *ChangeFilter) Actions(preds ...ActionsPredicate) *ChangeFilter {
+	return f.add(func(rc *ResourceChange) bool {
+		if rc.Change == nil {
+			return false
+		}
+		for _, pred := range preds {
+			if pred(rc.Change.Actions) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Provider selects changes to resources managed by any of the supplied
+// providers. Provider addresses are compared using
+// ProviderAddress.Equivalent, so "hashicorp/aws" matches resources
+// from "registry.opentofu.org/hashicorp/aws".
+func (f *ChangeFilter) Provider(providers ...string) *ChangeFilter {
+	parsed := make([]ProviderAddress, len(providers))
+	for i, provider := range providers {
+		p, err := ParseProviderAddress(provider)
+		if err != nil {
+			return f.fail(err)
+		}
+		parsed[i] = p
+	}
+	return f.add(func(rc *ResourceChange) bool {
+		addr, err := rc.ProviderAddress()
+		if err != nil {
+			return false
+		}
+		for _, p := range parsed {
+			if p.Equivalent(addr) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Tainted selects changes that replace a tainted object if b is true,
+// and all other changes if b is false.
+func (f *ChangeFilter) Tainted(b bool) *ChangeFilter {
+	return f.add(func(rc *ResourceChange) bool {
+		return (rc.ActionReason == ActionReasonReplaceBecauseTainted) == b
+	})
+}
+
+// Deposed selects changes to deposed objects if b is true, and to
+// current objects if b is false.
+func (f *ChangeFilter) Deposed(b bool) *ChangeFilter {
+	return f.add(func(rc *ResourceChange) bool {
+		return (rc.DeposedKey != "") == b
+	})
+}
+
+// Importing selects changes that import a resource if b is true, and
+// all other changes if b is false.
+func (f *ChangeFilter) Importing(b bool) *ChangeFilter {
+	return f.add(func(rc *ResourceChange) bool {
+		return (rc.Change != nil && rc.Change.Importing != nil) == b
+	})
+}
+
+// Match returns true if rc satisfies all the criteria of the filter.
+func (f *ChangeFilter) Match(rc *ResourceChange) bool {
+	if rc == nil {
+		return false
+	}
+	for _, pred := range f.predicates {
+		if !pred(rc) {
+			return false
+		}
+	}
+	return true
+}
+
+// Filter returns the changes matching the filter, in their original
+// order. It returns an error if the filter could not be built.
+func (f *ChangeFilter) Filter(changes []*ResourceChange) ([]*ResourceChange, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var ret []*ResourceChange
+	for _, rc := range changes {
+		if f.Match(rc) {
+			ret = append(ret, rc)
+		}
+	}
+	return ret, nil
+}
+
+// FilterDeferred returns the deferred changes whose ResourceChange
+// matches the filter, in their original order. It returns an error if
+// the filter could not be built.
+func (f *ChangeFilter) FilterDeferred(changes []*DeferredResourceChange) ([]*DeferredResourceChange, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	var ret []*DeferredResourceChange
+	for _, dc := range changes {
+		if dc != nil && f.Match(dc.ResourceChange) {
+			ret = append(ret, dc)
+		}
+	}
+	return ret, nil
+}
+
+// actionsPredicates maps the action names accepted by
+// ParseChangeFilter to their predicates.
+var actionsPredicates = map[string]ActionsPredicate{
+	"no-op":                 Actions.NoOp,
+	"create":                Actions.Create,
+	"read":                  Actions.Read,
+	"update":                Actions.Update,
+	"delete":                Actions.Delete,
+	"replace":               Actions.Replace,
+	"create-before-destroy": Actions.CreateBeforeDestroy,
+	"destroy-before-create": Actions.DestroyBeforeCreate,
+	"forget":                Actions.Forget,
+}
+
+// ParseChangeFilter parses a filter expression into a ChangeFilter.
+//
+// An expression is a whitespace-separated list of key=value terms,
+// all of which must match. A value can list several alternatives
+// separated by commas, any of which may match. Values containing
+// whitespace or commas can be double-quoted. The supported keys are:
+//
+// * mode: "managed" or "data"
+// * type: resource type glob patterns, see ChangeFilter.Type
+// * module: module instance prefixes, see ChangeFilter.Module
+// * action: one of "no-op", "create", "read", "update", "delete",
+// "replace", "create-before-destroy", "destroy-before-create" or
+// "forget"
+// * provider: provider addresses, see ChangeFilter.Provider
+// * tainted, deposed, importing: "true" or "false"
+//
+// For example:
+//
+//	mode=managed type=aws_iam_* module=module.prod action=replace,delete provider=hashicorp/aws
+func ParseChangeFilter(expr string) (*ChangeFilter, error) {
+	terms, err := splitFilterExpression(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	f := NewChangeFilter()
+	for _, term := range terms {
+		key, value, ok := strings.Cut(term, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid filter term %q: expected key=value", term)
+		}
+		values, err := splitFilterValues(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter term %q: %w", term, err)
+		}
+
+		switch key {
+		case "mode":
+			if len(values) != 1 {
+				return nil, fmt.Errorf("invalid filter term %q: expected a single mode", term)
+			}
+			mode := ResourceMode(values[0])
+			if mode != ManagedResourceMode && mode != DataResourceMode {
+				return nil, fmt.Errorf("invalid filter term %q: unknown mode %q", term, values[0])
+			}
+			f.Mode(mode)
+		case "type":
+			f.Type(values...)
+		case "module":
+			f.Module(values...)
+		case "provider":
+			f.Provider(values...)
+		case "action":
+			preds := make([]ActionsPredicate, len(values))
+			for i, v := range values {
+				pred, ok := actionsPredicates[v]
+				if !ok {
+					return nil, fmt.Errorf("invalid filter term %q: unknown action %q", term, v)
+				}
+				preds[i] = pred
+			}
+			f.Actions(preds...)
+		case "tainted", "deposed", "importing":
+			if len(values) != 1 {
+				return nil, fmt.Errorf("invalid filter term %q: expected a single boolean", term)
+			}
+			b, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid filter term %q: %w", term, err)
+			}
+			switch key {
+			case "tainted":
+				f.Tainted(b)
+			case "deposed":
+				f.Deposed(b)
+			case "importing":
+				f.Importing(b)
+			}
+		default:
+			return nil, fmt.Errorf("invalid filter term %q: unknown key %q", term, key)
+		}
+	}
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f, nil
+}
+
+// splitFilterExpression splits expr on whitespace outside of double
+// quotes.
+func splitFilterExpression(expr string) ([]string, error) {
+	var terms []string
+	var cur strings.Builder
+	inQuotes, escaped := false, false
+	for _, r := range expr {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				terms = append(terms, cur.String())
+				cur.Reset()
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("invalid filter expression %q: unterminated quote", expr)
+	}
+	if cur.Len() > 0 {
+		terms = append(terms, cur.String())
+	}
+	return terms, nil
+}
+
+// splitFilterValues splits a term value on commas outside of double
+// quotes, unquoting quoted values. Quotes inside brackets are kept, as
+// they are part of module instance keys. An empty value is only
+// accepted when quoted, ie: module="" for the root module.
+func splitFilterValues(value string) ([]string, error) {
+	var values []string
+	var cur strings.Builder
+	inQuotes, quoted, escaped := false, false, false
+	depth := 0
+	flush := func() error {
+		v := cur.String()
+		cur.Reset()
+		if quoted {
+			var err error
+			if v, err = strconv.Unquote(v); err != nil {
+				return err
+			}
+		} else if v == "" {
+			return fmt.Errorf("empty value")
+		}
+		values = append(values, v)
+		quoted = false
+		return nil
+	}
+	for _, r := range value {
+		switch {
+		case escaped:
+			escaped = false
+		case inQuotes && r == '\\':
+			escaped = true
+		case r == '"':
+			if depth == 0 && !inQuotes && cur.Len() == 0 {
+				quoted = true
+			}
+			inQuotes = !inQuotes
+		case !inQuotes && r == '[':
+			depth++
+		case !inQuotes && r == ']':
+			depth--
+		case !inQuotes && depth == 0 && r == ',':
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		cur.WriteRune(r)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
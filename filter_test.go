@@ -0,0 +1,210 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testFilterChanges() []*ResourceChange {
+	return []*ResourceChange{
+		{
+			Address:      "module.prod.aws_iam_role.a",
+			Mode:         ManagedResourceMode,
+			Type:         "aws_iam_role",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			Change:       &Change{Actions: Actions{ActionDelete, ActionCreate}},
+		},
+		{
+			Address:      `module.prod["eu"].module.iam.aws_iam_policy.b`,
+			Mode:         ManagedResourceMode,
+			Type:         "aws_iam_policy",
+			ProviderName: "registry.opentofu.org/hashicorp/aws",
+			Change:       &Change{Actions: Actions{ActionDelete}},
+		},
+		{
+			Address:      "module.prod.aws_iam_user.c",
+			Mode:         ManagedResourceMode,
+			Type:         "aws_iam_user",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			Change:       &Change{Actions: Actions{ActionUpdate}},
+		},
+		{
+			Address:      "module.production.aws_iam_role.d",
+			Mode:         ManagedResourceMode,
+			Type:         "aws_iam_role",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			Change:       &Change{Actions: Actions{ActionDelete}},
+		},
+		{
+			Address:      "module.prod.data.aws_iam_policy_document.e",
+			Mode:         DataResourceMode,
+			Type:         "aws_iam_policy_document",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			Change:       &Change{Actions: Actions{ActionDelete}},
+		},
+		{
+			Address:      "aws_instance.f",
+			Mode:         ManagedResourceMode,
+			Type:         "aws_instance",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			ActionReason: ActionReasonReplaceBecauseTainted,
+			Change:       &Change{Actions: Actions{ActionCreate, ActionDelete}},
+		},
+		{
+			Address:      "aws_instance.f",
+			Mode:         ManagedResourceMode,
+			Type:         "aws_instance",
+			ProviderName: "registry.terraform.io/hashicorp/aws",
+			DeposedKey:   "abcd",
+			Change:       &Change{Actions: Actions{ActionDelete}},
+		},
+		{
+			Address:      "random_id.g",
+			Mode:         ManagedResourceMode,
+			Type:         "random_id",
+			ProviderName: "registry.terraform.io/hashicorp/random",
+			Change:       &Change{Actions: Actions{ActionNoop}, Importing: &Importing{ID: "g"}},
+		},
+	}
+}
+
+func filteredAddresses(t *testing.T, f *ChangeFilter) []string {
+	t.Helper()
+
+	changes, err := f.Filter(testFilterChanges())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ret []string
+	for _, rc := range changes {
+		addr, err := rc.ParseAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret = append(ret, addr.String())
+	}
+	return ret
+}
+
+func TestChangeFilter(t *testing.T) {
+	cases := []struct {
+		name     string
+		filter   *ChangeFilter
+		expr     string
+		expected []string
+	}{
+		{
+			name: "iam replace or delete",
+			filter: NewChangeFilter().
+				Mode(ManagedResourceMode).
+				Type("aws_iam_*").
+				Module("module.prod").
+				Actions(Actions.Replace, Actions.Delete).
+				Provider("hashicorp/aws"),
+			expr: "mode=managed type=aws_iam_* module=module.prod action=replace,delete provider=hashicorp/aws",
+			expected: []string{
+				"module.prod.aws_iam_role.a",
+				`module.prod["eu"].module.iam.aws_iam_policy.b`,
+			},
+		},
+		{
+			name:     "module instance",
+			filter:   NewChangeFilter().Module(`module.prod["eu"]`),
+			expr:     `module=module.prod["eu"]`,
+			expected: []string{`module.prod["eu"].module.iam.aws_iam_policy.b`},
+		},
+		{
+			name:   "root module",
+			filter: NewChangeFilter().Module(""),
+			expr:   `module=""`,
+			expected: []string{
+				"aws_instance.f",
+				"aws_instance.f (deposed object abcd)",
+				"random_id.g",
+			},
+		},
+		{
+			name:     "tainted",
+			filter:   NewChangeFilter().Tainted(true),
+			expr:     "tainted=true",
+			expected: []string{"aws_instance.f"},
+		},
+		{
+			name:     "deposed",
+			filter:   NewChangeFilter().Deposed(true),
+			expr:     "deposed=true",
+			expected: []string{"aws_instance.f (deposed object abcd)"},
+		},
+		{
+			name:     "importing",
+			filter:   NewChangeFilter().Importing(true),
+			expr:     "importing=true",
+			expected: []string{"random_id.g"},
+		},
+		{
+			name:     "data",
+			filter:   NewChangeFilter().Mode(DataResourceMode),
+			expr:     "mode=data",
+			expected: []string{"module.prod.data.aws_iam_policy_document.e"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, filteredAddresses(t, tc.filter)); diff != "" {
+				t.Fatalf("unexpected changes from builder: %s", diff)
+			}
+
+			parsed, err := ParseChangeFilter(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, filteredAddresses(t, parsed)); diff != "" {
+				t.Fatalf("unexpected changes from expression: %s", diff)
+			}
+		})
+	}
+}
+
+func TestChangeFilterDeferred(t *testing.T) {
+	deferred := []*DeferredResourceChange{
+		{Reason: "provider_config_unknown", ResourceChange: testFilterChanges()[0]},
+		{Reason: "resource_config_unknown", ResourceChange: testFilterChanges()[7]},
+	}
+
+	actual, err := NewChangeFilter().Type("random_*").FilterDeferred(deferred)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 1 || actual[0] != deferred[1] {
+		t.Fatalf("unexpected deferred changes %#v", actual)
+	}
+}
+
+func TestParseChangeFilter_invalid(t *testing.T) {
+	for _, expr := range []string{
+		"mode",
+		"mode=resource",
+		"action=rebuild",
+		"type=[",
+		"module=foo",
+		"provider=a/b/c/d",
+		"tainted=maybe",
+		"color=red",
+		`type="unterminated`,
+		"type=a,,b",
+	} {
+		if _, err := ParseChangeFilter(expr); err == nil {
+			t.Errorf("expected error parsing %q", expr)
+		}
+	}
+
+	if _, err := NewChangeFilter().Type("[").Filter(nil); err == nil {
+		t.Error("expected error from invalid builder")
+	}
+}
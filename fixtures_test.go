@@ -0,0 +1,57 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// testLoadPlan decodes the plan of the named fixture in testdata.
+func testLoadPlan(t *testing.T, fixture string) *Plan {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(testFixtureDir, fixture, testGoldenPlanFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var plan *Plan
+	if err := json.NewDecoder(f).Decode(&plan); err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+// testLoadSchemas decodes the provider schemas of the named fixture in
+// testdata.
+func testLoadSchemas(t *testing.T, fixture string) *ProviderSchemas {
+	t.Helper()
+
+	f, err := os.Open(filepath.Join(testFixtureDir, fixture, testGoldenSchemasFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var schemas *ProviderSchemas
+	if err := json.NewDecoder(f).Decode(&schemas); err != nil {
+		t.Fatal(err)
+	}
+	return schemas
+}
+
+// testProviderSchemas returns provider schemas holding the given
+// managed resource schemas for a single provider.
+func testProviderSchemas(provider string, resources map[string]*Schema) *ProviderSchemas {
+	return &ProviderSchemas{
+		FormatVersion: "1.0",
+		Schemas: map[string]*ProviderSchema{
+			provider: {ResourceSchemas: resources},
+		},
+	}
+}
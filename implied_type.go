@@ -0,0 +1,125 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import "github.com/zclconf/go-cty/cty"
+
+// ImpliedType returns the object type of the values described by the
+// block, as derived by Terraform: each attribute and nested block
+// becomes an attribute of the object.
+//
+// A nil block implies an empty object type.
+func (b *SchemaBlock) ImpliedType() cty.Type {
+	if b == nil {
+		return cty.EmptyObject
+	}
+
+	attrTypes := make(map[string]cty.Type, len(b.Attributes)+len(b.NestedBlocks))
+	for name, attr := range b.Attributes {
+		attrTypes[name] = attr.ImpliedType()
+	}
+	for name, bt := range b.NestedBlocks {
+		attrTypes[name] = bt.ImpliedType()
+	}
+	return cty.Object(attrTypes)
+}
+
+// ImpliedType returns the type of the values of the nested block,
+// which is the implied type of its block wrapped according to its
+// nesting mode:
+//
+//   - single and group blocks are objects,
+//   - list blocks are lists of objects,
+//   - set blocks are sets of objects,
+//   - map blocks are maps of objects.
+//
+// Lists and maps of objects that contain dynamic types cannot be typed
+// in advance, as each element may have a different type. Their
+// implied type is cty.DynamicPseudoType, and their values are tuples
+// and objects respectively.
+func (bt *SchemaBlockType) ImpliedType() cty.Type {
+	if bt == nil {
+		return cty.DynamicPseudoType
+	}
+	return nestedImpliedType(bt.NestingMode, bt.Block.ImpliedType())
+}
+
+// ImpliedType returns the type of the values of the attribute: either
+// AttributeType, or the implied type of AttributeNestedType.
+//
+// An attribute without any type implies cty.DynamicPseudoType.
+func (a *SchemaAttribute) ImpliedType() cty.Type {
+	if a == nil {
+		return cty.DynamicPseudoType
+	}
+	if a.AttributeNestedType != nil {
+		return a.AttributeNestedType.ImpliedType()
+	}
+	if a.AttributeType == cty.NilType {
+		return cty.DynamicPseudoType
+	}
+	return a.AttributeType
+}
+
+// ImpliedType returns the type of the values of the nested attribute
+// type, which is the object type of its attributes wrapped according to
+// its nesting mode, as for SchemaBlockType.ImpliedType.
+//
+// Unlike nested blocks, list and map nested attributes are always
+// lists and maps of objects, even if the objects contain dynamic
+// types.
+func (t *SchemaNestedAttributeType) ImpliedType() cty.Type {
+	if t == nil {
+		return cty.DynamicPseudoType
+	}
+	return nestedAttributeImpliedType(t.NestingMode, t.objectType())
+}
+
+// objectType returns the type of a single object of the nested
+// attribute type, regardless of its nesting mode.
+func (t *SchemaNestedAttributeType) objectType() cty.Type {
+	attrTypes := make(map[string]cty.Type, len(t.Attributes))
+	for name, attr := range t.Attributes {
+		attrTypes[name] = attr.ImpliedType()
+	}
+	return cty.Object(attrTypes)
+}
+
+// nestedImpliedType wraps the object type ety of a nested block
+// according to the nesting mode.
+func nestedImpliedType(mode SchemaNestingMode, ety cty.Type) cty.Type {
+	switch mode {
+	case SchemaNestingModeSingle, SchemaNestingModeGroup:
+		return ety
+	case SchemaNestingModeList:
+		if ety.HasDynamicTypes() {
+			return cty.DynamicPseudoType
+		}
+		return cty.List(ety)
+	case SchemaNestingModeSet:
+		return cty.Set(ety)
+	case SchemaNestingModeMap:
+		if ety.HasDynamicTypes() {
+			return cty.DynamicPseudoType
+		}
+		return cty.Map(ety)
+	}
+	return cty.DynamicPseudoType
+}
+
+// nestedAttributeImpliedType wraps the object type ety of a nested
+// attribute type according to the nesting mode.
+func nestedAttributeImpliedType(mode SchemaNestingMode, ety cty.Type) cty.Type {
+	switch mode {
+	case SchemaNestingModeSingle, SchemaNestingModeGroup:
+		return ety
+	case SchemaNestingModeList:
+		return cty.List(ety)
+	case SchemaNestingModeSet:
+		return cty.Set(ety)
+	case SchemaNestingModeMap:
+		return cty.Map(ety)
+	}
+	return cty.DynamicPseudoType
+}
@@ -0,0 +1,140 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSchemaBlockImpliedType(t *testing.T) {
+	inner := &SchemaBlock{
+		Attributes: map[string]*SchemaAttribute{
+			"name": {AttributeType: cty.String},
+		},
+	}
+	dynamic := &SchemaBlock{
+		Attributes: map[string]*SchemaAttribute{
+			"value": {AttributeType: cty.DynamicPseudoType},
+		},
+	}
+	innerTy := cty.Object(map[string]cty.Type{"name": cty.String})
+
+	block := &SchemaBlock{
+		Attributes: map[string]*SchemaAttribute{
+			"id":      {AttributeType: cty.String},
+			"untyped": {},
+			"nested": {
+				AttributeNestedType: &SchemaNestedAttributeType{
+					NestingMode: SchemaNestingModeMap,
+					Attributes: map[string]*SchemaAttribute{
+						"port": {AttributeType: cty.Number},
+					},
+				},
+			},
+		},
+		NestedBlocks: map[string]*SchemaBlockType{
+			"single":       {NestingMode: SchemaNestingModeSingle, Block: inner},
+			"group":        {NestingMode: SchemaNestingModeGroup, Block: inner},
+			"list":         {NestingMode: SchemaNestingModeList, Block: inner},
+			"set":          {NestingMode: SchemaNestingModeSet, Block: inner},
+			"map":          {NestingMode: SchemaNestingModeMap, Block: inner},
+			"dynamic_list": {NestingMode: SchemaNestingModeList, Block: dynamic},
+			"dynamic_map":  {NestingMode: SchemaNestingModeMap, Block: dynamic},
+			"dynamic_set":  {NestingMode: SchemaNestingModeSet, Block: dynamic},
+			"empty":        {NestingMode: SchemaNestingModeList},
+		},
+	}
+
+	expected := cty.Object(map[string]cty.Type{
+		"id":           cty.String,
+		"untyped":      cty.DynamicPseudoType,
+		"nested":       cty.Map(cty.Object(map[string]cty.Type{"port": cty.Number})),
+		"single":       innerTy,
+		"group":        innerTy,
+		"list":         cty.List(innerTy),
+		"set":          cty.Set(innerTy),
+		"map":          cty.Map(innerTy),
+		"dynamic_list": cty.DynamicPseudoType,
+		"dynamic_map":  cty.DynamicPseudoType,
+		"dynamic_set":  cty.Set(cty.Object(map[string]cty.Type{"value": cty.DynamicPseudoType})),
+		"empty":        cty.List(cty.EmptyObject),
+	})
+
+	if actual := block.ImpliedType(); !actual.Equals(expected) {
+		t.Fatalf("unexpected type\nexpected: %#v\nactual:   %#v", expected, actual)
+	}
+
+	if actual := (*SchemaBlock)(nil).ImpliedType(); !actual.Equals(cty.EmptyObject) {
+		t.Fatalf("expected empty object for nil block, got %#v", actual)
+	}
+}
+
+func TestSchemaNestedAttributeTypeImpliedType(t *testing.T) {
+	attrs := map[string]*SchemaAttribute{
+		"a": {AttributeType: cty.String},
+		"b": {
+			AttributeNestedType: &SchemaNestedAttributeType{
+				NestingMode: SchemaNestingModeSingle,
+				Attributes: map[string]*SchemaAttribute{
+					"c": {AttributeType: cty.Bool},
+				},
+			},
+		},
+	}
+	objTy := cty.Object(map[string]cty.Type{
+		"a": cty.String,
+		"b": cty.Object(map[string]cty.Type{"c": cty.Bool}),
+	})
+
+	cases := map[SchemaNestingMode]cty.Type{
+		SchemaNestingModeSingle: objTy,
+		SchemaNestingModeList:   cty.List(objTy),
+		SchemaNestingModeSet:    cty.Set(objTy),
+		SchemaNestingModeMap:    cty.Map(objTy),
+	}
+	for mode, expected := range cases {
+		t.Run(string(mode), func(t *testing.T) {
+			nt := &SchemaNestedAttributeType{NestingMode: mode, Attributes: attrs}
+			if actual := nt.ImpliedType(); !actual.Equals(expected) {
+				t.Fatalf("unexpected type\nexpected: %#v\nactual:   %#v", expected, actual)
+			}
+		})
+	}
+
+	// Unlike nested blocks, nested attributes never become dynamic.
+	dynamicAttrs := map[string]*SchemaAttribute{
+		"v": {AttributeType: cty.List(cty.DynamicPseudoType)},
+	}
+	dynamicTy := cty.Object(map[string]cty.Type{"v": cty.List(cty.DynamicPseudoType)})
+	dynamicCases := map[SchemaNestingMode]cty.Type{
+		SchemaNestingModeList: cty.List(dynamicTy),
+		SchemaNestingModeMap:  cty.Map(dynamicTy),
+	}
+	for mode, expected := range dynamicCases {
+		t.Run(string(mode)+"/dynamic", func(t *testing.T) {
+			nt := &SchemaNestedAttributeType{NestingMode: mode, Attributes: dynamicAttrs}
+			if actual := nt.ImpliedType(); !actual.Equals(expected) {
+				t.Fatalf("unexpected type\nexpected: %#v\nactual:   %#v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestSchemaBlockImpliedType_fixture(t *testing.T) {
+	schemas := testLoadSchemas(t, "nested_attributes")
+	provider := schemas.Schemas["registry.terraform.io/hashicorp/awscc"].ConfigSchema
+	actual := provider.Block.ImpliedType()
+	expected := cty.Object(map[string]cty.Type{
+		"access_key": cty.String,
+		"assume_role": cty.Object(map[string]cty.Type{
+			"duration":    cty.String,
+			"external_id": cty.String,
+		}),
+	})
+	if !actual.Equals(expected) {
+		t.Fatalf("unexpected type\nexpected: %#v\nactual:   %#v", expected, actual)
+	}
+}
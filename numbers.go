@@ -0,0 +1,217 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"runtime"
+	"sync"
+)
+
+// bigFloatPrec is the precision of the numbers decoded with
+// NumberBigFloat, which is the precision cty uses to parse numbers.
+const bigFloatPrec = 512
+
+// bigFloatNumber converts a json.Number to a *big.Float. Other values
+// are returned unchanged.
+func bigFloatNumber(v interface{}) interface{} {
+	n, ok := v.(json.Number)
+	if !ok {
+		return v
+	}
+	f, _, err := big.ParseFloat(n.String(), 10, bigFloatPrec, big.ToNearestEven)
+	if err != nil {
+		return v
+	}
+	if n.String() != f.Text('f', -1) {
+		bigFloatLiterals.remember(f, n)
+	}
+	return f
+}
+
+// jsonNumber converts a *big.Float to a json.Number. A number decoded
+// with NumberBigFloat keeps the literal it was decoded from, unless it
+// has been changed since. Other numbers are formatted as Terraform
+// formats them. Other values are returned unchanged.
+func jsonNumber(v interface{}) interface{} {
+	f, ok := v.(*big.Float)
+	if !ok || f == nil {
+		return v
+	}
+	if n, ok := bigFloatLiterals.lookup(f); ok {
+		return n
+	}
+	return json.Number(f.Text('f', -1))
+}
+
+// bigFloatLiterals holds the literals of the numbers decoded with
+// NumberBigFloat that jsonNumber would not format the same way, such
+// as 1e3 or 1.50, so that they round-trip byte-exact. Terraform never
+// writes such literals, so it is usually empty.
+var bigFloatLiterals = &literalTable{entries: make(map[uintptr]numberLiteral)}
+
+// literalTable maps numbers to their literal. It is keyed by the
+// address of the numbers rather than by pointer, so as not to keep
+// them alive, and entries are removed by a finalizer once a number is
+// no longer used.
+type literalTable struct {
+	mu      sync.Mutex
+	entries map[uintptr]numberLiteral
+}
+
+// numberLiteral is the literal a number was decoded from, along with
+// its decoded value to tell whether the number was changed since.
+type numberLiteral struct {
+	literal json.Number
+	value   *big.Float
+}
+
+func (t *literalTable) remember(f *big.Float, n json.Number) {
+	t.mu.Lock()
+	t.entries[reflect.ValueOf(f).Pointer()] = numberLiteral{literal: n, value: new(big.Float).Set(f)}
+	t.mu.Unlock()
+	runtime.SetFinalizer(f, t.forget)
+}
+
+func (t *literalTable) forget(f *big.Float) {
+	t.mu.Lock()
+	delete(t.entries, reflect.ValueOf(f).Pointer())
+	t.mu.Unlock()
+}
+
+func (t *literalTable) lookup(f *big.Float) (json.Number, bool) {
+	t.mu.Lock()
+	e, ok := t.entries[reflect.ValueOf(f).Pointer()]
+	t.mu.Unlock()
+	if !ok || e.value.Cmp(f) != 0 {
+		return "", false
+	}
+	return e.literal, true
+}
+
+// bigFloatInt returns f as an int if it is an integer within range.
+func bigFloatInt(f *big.Float) (int, bool) {
+	if f == nil || !f.IsInt() {
+		return 0, false
+	}
+	i, acc := f.Int64()
+	if acc != big.Exact || int64(int(i)) != i {
+		return 0, false
+	}
+	return int(i), true
+}
+
+// toBigFloats converts the numbers held by the decoded struct that ptr
+// points to from json.Number to *big.Float, in place.
+func toBigFloats(ptr interface{}) {
+	v := reflect.ValueOf(ptr).Elem()
+	v.Set(convertNumbers(v, bigFloatNumber))
+}
+
+// jsonNumbers returns the JSON value v, or a copy of it where numbers
+// decoded with NumberBigFloat have been converted to json.Number, as
+// encoding/json would otherwise marshal them as strings. The types
+// holding JSON values call it when they are marshaled.
+func jsonNumbers(v interface{}) interface{} {
+	if !jsonContainsBigFloat(v) {
+		return v
+	}
+	return convertJSONNumbers(v, jsonNumber)
+}
+
+// convertNumbers returns a copy of v where the numbers of the JSON
+// values held by its interface fields have been converted by fn.
+func convertNumbers(v reflect.Value, fn func(interface{}) interface{}) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(reflect.ValueOf(convertJSONNumbers(v.Interface(), fn)))
+		return out
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(convertNumbers(v.Elem(), fn))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).IsExported() {
+				out.Field(i).Set(convertNumbers(v.Field(i), fn))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(convertNumbers(v.Index(i), fn))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), convertNumbers(iter.Value(), fn))
+		}
+		return out
+	}
+	return v
+}
+
+// convertJSONNumbers returns a copy of the JSON value v where numbers
+// have been converted by fn.
+func convertJSONNumbers(v interface{}, fn func(interface{}) interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, cv := range v {
+			out[k] = convertJSONNumbers(cv, fn)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, cv := range v {
+			out[i] = convertJSONNumbers(cv, fn)
+		}
+		return out
+	case json.Number, *big.Float:
+		return fn(v)
+	}
+	return v
+}
+
+// jsonContainsBigFloat returns true if the JSON value v holds a
+// *big.Float.
+func jsonContainsBigFloat(v interface{}) bool {
+	switch v := v.(type) {
+	case *big.Float:
+		return true
+	case map[string]interface{}:
+		for _, cv := range v {
+			if jsonContainsBigFloat(cv) {
+				return true
+			}
+		}
+	case []interface{}:
+		for _, cv := range v {
+			if jsonContainsBigFloat(cv) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,225 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// testBigFloatPlanFileName holds numbers that cannot round-trip through
+// float64, so it is kept out of the fixtures read by testParse.
+const testBigFloatPlanFileName = "testdata/numerics/plan-bigfloat.json"
+
+func testLoadNumbersPlan(t *testing.T, numbers NumberMode) *Plan {
+	t.Helper()
+
+	b, err := os.ReadFile(testBigFloatPlanFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plan := &Plan{}
+	plan.SetDecodeOptions(DecodeOptions{Numbers: numbers})
+	if err := json.Unmarshal(b, plan); err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func TestPlanDecodeOptions_bigFloat(t *testing.T) {
+	plan := testLoadNumbersPlan(t, NumberBigFloat)
+
+	after := plan.ResourceChanges[0].Change.After.(map[string]interface{})
+	planned := plan.PlannedValues.RootModule.Resources[0].AttributeValues
+	exprs := plan.Config.RootModule.Resources[0].Expressions
+
+	cases := map[string]struct {
+		value    interface{}
+		expected string
+	}{
+		"after large_id":      {after["large_id"], "9223372036854775807"},
+		"after unsigned_id":   {after["unsigned_id"], "18446744073709551615"},
+		"after precise":       {after["precise"], "3.14159265358979323846264338327950288"},
+		"after decimal":       {after["configurable_attribute"], "1.23"},
+		"planned large_id":    {planned["large_id"], "9223372036854775807"},
+		"planned output":      {plan.PlannedValues.Outputs["unsigned_id"].Value, "18446744073709551615"},
+		"output change":       {plan.OutputChanges["unsigned_id"].After, "18446744073709551615"},
+		"prior state output":  {plan.PriorState.Values.Outputs["previous_id"].Value, "9007199254740993"},
+		"expression large_id": {exprs["large_id"].ConstantValue, "9223372036854775807"},
+		"expression precise":  {exprs["precise"].ConstantValue, "3.14159265358979323846264338327950288"},
+		"config output":       {plan.Config.RootModule.Outputs["unsigned_id"].Expression.ConstantValue, "18446744073709551615"},
+		"expression unsigned": {exprs["unsigned_id"].ConstantValue, "18446744073709551615"},
+		"after id":            {after["id"], ""},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			f, ok := tc.value.(*big.Float)
+			if tc.expected == "" {
+				if ok {
+					t.Fatalf("expected a non-number, got %s", f.Text('f', -1))
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected *big.Float, got %T", tc.value)
+			}
+			if f.Prec() != bigFloatPrec {
+				t.Fatalf("expected precision %d, got %d", bigFloatPrec, f.Prec())
+			}
+			if actual := f.Text('f', -1); actual != tc.expected {
+				t.Fatalf("expected %s, got %s", tc.expected, actual)
+			}
+		})
+	}
+
+	unsigned, _ := new(big.Int).SetString("18446744073709551615", 10)
+	if i, _ := after["unsigned_id"].(*big.Float).Int(nil); i.Cmp(unsigned) != 0 {
+		t.Fatalf("expected %s, got %s", unsigned, i)
+	}
+}
+
+func TestPlanDecodeOptions_bigFloatMarshal(t *testing.T) {
+	expected, err := os.ReadFile(testBigFloatPlanFileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, numbers := range map[string]NumberMode{
+		"json-number": NumberJSONNumber,
+		"big-float":   NumberBigFloat,
+	} {
+		t.Run(name, func(t *testing.T) {
+			actual, err := json.Marshal(testLoadNumbersPlan(t, numbers))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual)+"\n" != string(expected) {
+				t.Fatalf("unexpected round trip:\n%s", actual)
+			}
+		})
+	}
+
+	// Marshaling converts a copy, leaving the plan untouched.
+	plan := testLoadNumbersPlan(t, NumberBigFloat)
+	if _, err := json.Marshal(plan); err != nil {
+		t.Fatal(err)
+	}
+	after := plan.ResourceChanges[0].Change.After.(map[string]interface{})
+	if _, ok := after["large_id"].(*big.Float); !ok {
+		t.Fatalf("expected *big.Float after marshaling, got %T", after["large_id"])
+	}
+}
+
+func TestPlanDecodeOptions_bigFloatMarshalParts(t *testing.T) {
+	plan := testLoadNumbersPlan(t, NumberBigFloat)
+	numbers := testLoadNumbersPlan(t, NumberJSONNumber)
+
+	cases := map[string]struct {
+		value, expected interface{}
+	}{
+		"plan value":      {*plan, *numbers},
+		"resource change": {plan.ResourceChanges[0], numbers.ResourceChanges[0]},
+		"state module":    {plan.PlannedValues.RootModule, numbers.PlannedValues.RootModule},
+		"config module":   {plan.Config.RootModule, numbers.Config.RootModule},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			expected, err := json.Marshal(tc.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(actual) != string(expected) {
+				t.Fatalf("expected:\n%s\ngot:\n%s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestJSONValuesEqual(t *testing.T) {
+	a := bigFloatNumber(json.Number("9007199254740993"))
+	b := bigFloatNumber(json.Number("9007199254740993.0"))
+	c := bigFloatNumber(json.Number("9007199254740992"))
+
+	if !jsonValuesEqual(map[string]interface{}{"n": a}, map[string]interface{}{"n": b}) {
+		t.Fatal("expected equal numbers to be equal")
+	}
+	if jsonValuesEqual([]interface{}{a}, []interface{}{c}) {
+		t.Fatal("expected different numbers to differ")
+	}
+	if jsonValuesEqual(a, float64(9007199254740992)) {
+		t.Fatal("expected numbers of different types to differ")
+	}
+	if !jsonValuesEqual(map[string]interface{}{"s": "x"}, map[string]interface{}{"s": "x"}) {
+		t.Fatal("expected equal objects to be equal")
+	}
+}
+
+func TestBigFloatValues(t *testing.T) {
+	index := bigFloatNumber(json.Number("2"))
+	path, err := AttributePathFromIndexes([]interface{}{"disks", index})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if path.String() != "disks[2]" {
+		t.Fatalf("unexpected path %s", path)
+	}
+	if _, err := AttributePathFromIndexes([]interface{}{bigFloatNumber(json.Number("2.5"))}); err == nil {
+		t.Fatal("expected error for fractional index")
+	}
+
+	key, err := InstanceKeyFromValue(index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != IntKey(2) {
+		t.Fatalf("unexpected key %#v", key)
+	}
+
+	v, err := numberValue(nil, bigFloatNumber(json.Number("18446744073709551615")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := cty.MustParseNumberVal("18446744073709551615")
+	if !v.RawEquals(expected) {
+		t.Fatalf("expected %#v, got %#v", expected, v)
+	}
+}
+
+func TestStateDecodeOptions_bigFloatLiterals(t *testing.T) {
+	doc := `{"format_version":"1.0","values":{"outputs":{"scaled":{"sensitive":false,"value":[1e3,1.50,2]}},"root_module":{}}}`
+
+	state := &State{}
+	state.SetDecodeOptions(DecodeOptions{Numbers: NumberBigFloat})
+	if err := json.Unmarshal([]byte(doc), state); err != nil {
+		t.Fatal(err)
+	}
+
+	actual, err := json.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(actual) != doc {
+		t.Fatalf("unexpected round trip:\n%s", actual)
+	}
+
+	// A number changed since it was decoded loses its literal.
+	scaled := state.Values.Outputs["scaled"].Value.([]interface{})
+	scaled[0].(*big.Float).SetInt64(1001)
+	actual, err = json.Marshal(state.Values.Outputs["scaled"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"sensitive":false,"value":[1001,1.50,2]}`; string(actual) != expected {
+		t.Fatalf("expected %s, got %s", expected, actual)
+	}
+}
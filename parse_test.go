@@ -19,7 +19,17 @@ const testGoldenPlanFileName = "plan.json"
 const testGoldenStateFileName = "state.json"
 const testGoldenSchemasFileName = "schemas.json"
 
+// testDecodeOptionsSetter is implemented by the types that accept
+// DecodeOptions.
+type testDecodeOptionsSetter interface {
+	SetDecodeOptions(DecodeOptions)
+}
+
 func testParse(t *testing.T, filename string, typ reflect.Type) {
+	testParseWithOptions(t, filename, typ, DecodeOptions{})
+}
+
+func testParseWithOptions(t *testing.T, filename string, typ reflect.Type, opts DecodeOptions) {
 	entries, err := os.ReadDir(testFixtureDir)
 	if err != nil {
 		t.Fatalf("err: %s", err)
@@ -40,6 +50,9 @@ func testParse(t *testing.T, filename string, typ reflect.Type) {
 			}
 
 			parsed := reflect.New(typ).Interface()
+			if setter, ok := parsed.(testDecodeOptionsSetter); ok {
+				setter.SetDecodeOptions(opts)
+			}
 			dec := json.NewDecoder(bytes.NewBuffer(expected))
 			dec.DisallowUnknownFields()
 			if err = dec.Decode(parsed); err != nil {
@@ -68,6 +81,10 @@ func TestParsePlan(t *testing.T) {
 	testParse(t, testGoldenPlanFileName, reflect.TypeOf(Plan{}))
 }
 
+func TestParsePlan_bigFloat(t *testing.T) {
+	testParseWithOptions(t, testGoldenPlanFileName, reflect.TypeOf(Plan{}), DecodeOptions{Numbers: NumberBigFloat})
+}
+
 func TestParseSchemas(t *testing.T) {
 	testParse(t, testGoldenSchemasFileName, reflect.TypeOf(ProviderSchemas{}))
 }
@@ -76,6 +93,10 @@ func TestParseState(t *testing.T) {
 	testParse(t, testGoldenStateFileName, reflect.TypeOf(State{}))
 }
 
+func TestParseState_bigFloat(t *testing.T) {
+	testParseWithOptions(t, testGoldenStateFileName, reflect.TypeOf(State{}), DecodeOptions{Numbers: NumberBigFloat})
+}
+
 func lineAt(text []byte, offs int) []byte {
 	i := offs
 	for i < len(text) && text[i] != '\n' {
@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// AttributePathStep is a single step of an AttributePath. It is one of
+// AttributeStep, IndexStep or KeyStep.
+type AttributePathStep interface {
+	attributePathStep()
+
+	// Index returns the step using the convention of
+	// Change.ReplacePaths: an int for IndexStep, and a string for both
+	// AttributeStep and KeyStep.
+	Index() interface{}
+}
+
+// AttributeStep selects an attribute of an object, or a nested block,
+// by name.
+type AttributeStep string
+
+func (AttributeStep) attributePathStep() {}
+
+func (s AttributeStep) Index() interface{} {
+	return string(s)
+}
+
+// IndexStep selects an element of a list, set or tuple by position.
+type IndexStep int
+
+func (IndexStep) attributePathStep() {}
+
+func (s IndexStep) Index() interface{} {
+	return int(s)
+}
+
+// KeyStep selects an element of a map by key.
+type KeyStep string
+
+func (KeyStep) attributePathStep() {}
+
+func (s KeyStep) Index() interface{} {
+	return string(s)
+}
+
+// AttributePath is the typed path of a value nested within a resource,
+// starting from its top-level attributes. An empty path refers to the
+// whole resource object.
+//
+// When derived from JSON values alone, object attributes and map keys
+// cannot be told apart. Such steps are represented as AttributeStep.
+type AttributePath []AttributePathStep
+
+// Indexes returns the path using the convention of
+// Change.ReplacePaths.
+func (p AttributePath) Indexes() []interface{} {
+	ret := make([]interface{}, len(p))
+	for i, step := range p {
+		ret[i] = step.Index()
+	}
+	return ret
+}
+
+// Equal returns true if both paths are identical.
+func (p AttributePath) Equal(other AttributePath) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// EqualIndexes returns true if both paths are the same using the
+// convention of Change.ReplacePaths, which does not distinguish
+// between AttributeStep and KeyStep.
+func (p AttributePath) EqualIndexes(other AttributePath) bool {
+	if len(p) != len(other) {
+		return false
+	}
+	for i := range p {
+		if p[i].Index() != other[i].Index() {
+			return false
+		}
+	}
+	return true
+}
+
+// HasPrefix returns true if prefix is the same as, or a parent of, p.
+// Steps are compared using the convention of Change.ReplacePaths.
+func (p AttributePath) HasPrefix(prefix AttributePath) bool {
+	return len(prefix) <= len(p) && p[:len(prefix)].EqualIndexes(prefix)
+}
+
+// Copy returns a copy of p that does not share its backing array.
+func (p AttributePath) Copy() AttributePath {
+	if p == nil {
+		return nil
+	}
+	ret := make(AttributePath, len(p))
+	copy(ret, p)
+	return ret
+}
+
+// String returns the path in the form `foo.bar[0]["k"]`. Attribute
+// names that are not valid identifiers are rendered as bracketed keys.
+func (p AttributePath) String() string {
+	var b strings.Builder
+	for i, step := range p {
+		switch step := step.(type) {
+		case AttributeStep:
+			if !isIdentifier(string(step)) {
+				b.WriteString("[" + quoteAddressString(string(step)) + "]")
+				continue
+			}
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(string(step))
+		case IndexStep:
+			b.WriteString("[" + strconv.Itoa(int(step)) + "]")
+		case KeyStep:
+			b.WriteString("[" + quoteAddressString(string(step)) + "]")
+		}
+	}
+	return b.String()
+}
+
+// CtyPath converts the path to a cty.Path. AttributeStep becomes
+// cty.GetAttrStep, while IndexStep and KeyStep become cty.IndexStep
+// with a number and string key respectively.
+func (p AttributePath) CtyPath() cty.Path {
+	ret := make(cty.Path, 0, len(p))
+	for _, step := range p {
+		switch step := step.(type) {
+		case AttributeStep:
+			ret = ret.GetAttr(string(step))
+		case IndexStep:
+			ret = ret.IndexInt(int(step))
+		case KeyStep:
+			ret = ret.IndexString(string(step))
+		}
+	}
+	return ret
+}
+
+// AttributePathFromCtyPath converts a cty.Path to an AttributePath. It
+// returns an error if an index is unknown, null, or neither a string
+// nor an integer.
+func AttributePathFromCtyPath(path cty.Path) (AttributePath, error) {
+	ret := make(AttributePath, 0, len(path))
+	for _, step := range path {
+		switch step := step.(type) {
+		case cty.GetAttrStep:
+			ret = append(ret, AttributeStep(step.Name))
+		case cty.IndexStep:
+			key := step.Key
+			switch {
+			case !key.IsKnown() || key.IsNull():
+				return nil, fmt.Errorf("invalid path step %d: index must be known and not null", len(ret))
+			case key.Type() == cty.String:
+				ret = append(ret, KeyStep(key.AsString()))
+			case key.Type() == cty.Number:
+				i, accuracy := key.AsBigFloat().Int64()
+				if accuracy != big.Exact || int64(int(i)) != i {
+					return nil, fmt.Errorf("invalid path step %d: index %v is not an integer", len(ret), key.AsBigFloat())
+				}
+				ret = append(ret, IndexStep(i))
+			default:
+				return nil, fmt.Errorf("invalid path step %d: unsupported index type %s", len(ret), key.Type().FriendlyName())
+			}
+		default:
+			return nil, fmt.Errorf("invalid path step %d: unsupported step type %T", len(ret), step)
+		}
+	}
+	return ret, nil
+}
+
+// AttributePathFromIndexes decodes a path using the convention of
+// Change.ReplacePaths, where each step is either a string or an
+// integer. Strings become AttributeStep, as object attributes and map
+// keys cannot be told apart.
+func AttributePathFromIndexes(indexes []interface{}) (AttributePath, error) {
+	path := make(AttributePath, 0, len(indexes))
+	for _, index := range indexes {
+		switch index := index.(type) {
+		case string:
+			path = append(path, AttributeStep(index))
+		case int:
+			path = append(path, IndexStep(index))
+		case float64:
+			if index != math.Trunc(index) {
+				return nil, fmt.Errorf("invalid path step %d: index %v is not an integer", len(path), index)
+			}
+			path = append(path, IndexStep(index))
+		case json.Number:
+			i, err := strconv.Atoi(index.String())
+			if err != nil {
+				return nil, fmt.Errorf("invalid path step %d: index %s is not an integer", len(path), index)
+			}
+			path = append(path, IndexStep(i))
+		case *big.Float:
+			i, ok := bigFloatInt(index)
+			if !ok {
+				return nil, fmt.Errorf("invalid path step %d: index %s is not an integer", len(path), index.Text('f', -1))
+			}
+			path = append(path, IndexStep(i))
+		default:
+			return nil, fmt.Errorf("invalid path step %d: unsupported index type %T", len(path), index)
+		}
+	}
+	return path, nil
+}
+
+// ReplaceAttributePaths decodes ReplacePaths. An error is returned if
+// any of them is malformed.
+func (c *Change) ReplaceAttributePaths() ([]AttributePath, error) {
+	ret := make([]AttributePath, 0, len(c.ReplacePaths))
+	for _, rp := range c.ReplacePaths {
+		indexes, ok := rp.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("invalid replace path: expected a list, got %T", rp)
+		}
+		path, err := AttributePathFromIndexes(indexes)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, path)
+	}
+	return ret, nil
+}
+
+// Path decodes Attribute. As with Change.ReplacePaths, strings become
+// AttributeStep, as object attributes and map keys cannot be told
+// apart.
+func (ra ResourceAttribute) Path() (AttributePath, error) {
+	indexes := make([]interface{}, len(ra.Attribute))
+	for i, raw := range ra.Attribute {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&indexes[i]); err != nil {
+			return nil, fmt.Errorf("invalid attribute path step %s: %w", raw, err)
+		}
+	}
+	return AttributePathFromIndexes(indexes)
+}
+
+// ParseAttributePath parses a path in the form returned by String, ie:
+// `foo.bar[0]["k"]`. Bracketed strings are parsed as KeyStep, so a path
+// containing an AttributeStep that is not a valid identifier parses to
+// a path that is only equal to the original with EqualIndexes. An empty
+// string parses to the empty path.
+func ParseAttributePath(s string) (AttributePath, error) {
+	p := &addressParser{src: s, what: "attribute path"}
+	var path AttributePath
+	for !p.eof() {
+		switch {
+		case strings.HasPrefix(p.rest(), "["):
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, err
+			}
+			switch key := key.(type) {
+			case IntKey:
+				path = append(path, IndexStep(key))
+			case StringKey:
+				path = append(path, KeyStep(key))
+			}
+		case len(path) == 0 || p.consume("."):
+			name, err := p.parseIdent()
+			if err != nil {
+				return nil, err
+			}
+			path = append(path, AttributeStep(name))
+		default:
+			return nil, p.errorf("expected \".\" or \"[\"")
+		}
+	}
+	return path, nil
+}
+
+// less returns true if p sorts before other. Paths are compared step
+// by step, attribute names and map keys as strings and indexes
+// numerically, with indexes first when the kinds differ. A path sorts
+// before the paths it is a prefix of.
+func (p AttributePath) less(other AttributePath) bool {
+	for i := 0; i < len(p) && i < len(other); i++ {
+		a, aIndex := p[i].(IndexStep)
+		b, bIndex := other[i].(IndexStep)
+		switch {
+		case aIndex && bIndex:
+			if a != b {
+				return a < b
+			}
+		case aIndex != bIndex:
+			return aIndex
+		default:
+			as, bs := p[i].Index().(string), other[i].Index().(string)
+			if as != bs {
+				return as < bs
+			}
+		}
+	}
+	return len(p) < len(other)
+}
+
+// child returns a new path with step appended, never sharing the
+// backing array of p.
+func (p AttributePath) child(step AttributePathStep) AttributePath {
+	ret := make(AttributePath, len(p), len(p)+1)
+	copy(ret, p)
+	return append(ret, step)
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case i > 0 && (r == '-' || (r >= '0' && r <= '9')):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestAttributePathString(t *testing.T) {
+	cases := []struct {
+		path     AttributePath
+		expected string
+	}{
+		{nil, ""},
+		{AttributePath{AttributeStep("foo")}, "foo"},
+		{AttributePath{AttributeStep("foo"), AttributeStep("bar"), IndexStep(0), KeyStep("k")}, `foo.bar[0]["k"]`},
+		{AttributePath{IndexStep(1), AttributeStep("a")}, "[1].a"},
+		{AttributePath{AttributeStep("tags"), AttributeStep("a.b")}, `tags["a.b"]`},
+		{AttributePath{KeyStep(`quo"te`)}, `["quo\"te"]`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if actual := tc.path.String(); actual != tc.expected {
+				t.Fatalf("expected %q, got %q", tc.expected, actual)
+			}
+
+			parsed, err := ParseAttributePath(tc.expected)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !parsed.EqualIndexes(tc.path) {
+				t.Fatalf("expected %#v, got %#v", tc.path, parsed)
+			}
+		})
+	}
+}
+
+func TestParseAttributePath(t *testing.T) {
+	actual, err := ParseAttributePath(`foo.bar[0]["k"]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AttributePath{AttributeStep("foo"), AttributeStep("bar"), IndexStep(0), KeyStep("k")}
+	if !actual.Equal(expected) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	for _, s := range []string{".foo", "foo.", "foo..bar", "foo[", "foo[x]", `foo["k"`, "foo bar", "foo[0]bar"} {
+		if _, err := ParseAttributePath(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestAttributePathFromIndexes(t *testing.T) {
+	actual, err := AttributePathFromIndexes([]interface{}{"objects", float64(0), json.Number("1"), 2, "val"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := AttributePath{AttributeStep("objects"), IndexStep(0), IndexStep(1), IndexStep(2), AttributeStep("val")}
+	if !actual.Equal(expected) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	for _, indexes := range [][]interface{}{{float64(1.5)}, {json.Number("1e3x")}, {true}} {
+		if _, err := AttributePathFromIndexes(indexes); err == nil {
+			t.Errorf("expected error decoding %#v", indexes)
+		}
+	}
+}
+
+func TestChangeReplaceAttributePaths(t *testing.T) {
+	c := &Change{
+		ReplacePaths: []interface{}{
+			[]interface{}{"ami"},
+			[]interface{}{"disks", float64(0), "size"},
+		},
+	}
+
+	actual, err := c.ReplaceAttributePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(actual) != 2 || actual[0].String() != "ami" || actual[1].String() != "disks[0].size" {
+		t.Fatalf("unexpected paths %v", actual)
+	}
+
+	c.ReplacePaths = append(c.ReplacePaths, "ami")
+	if _, err := c.ReplaceAttributePaths(); err == nil {
+		t.Fatal("expected error for malformed replace path")
+	}
+}
+
+func TestResourceAttributePath(t *testing.T) {
+	plan := testLoadPlan(t, "120_basic")
+	if len(plan.RelevantAttributes) == 0 {
+		t.Fatal("expected relevant attributes in fixture")
+	}
+	for _, ra := range plan.RelevantAttributes {
+		if _, err := ra.Path(); err != nil {
+			t.Fatalf("%s: %s", ra.Resource, err)
+		}
+	}
+
+	ra := ResourceAttribute{
+		Resource:  "null_resource.foo",
+		Attribute: []json.RawMessage{[]byte(`"objects"`), []byte(`0`), []byte(`"val"`)},
+	}
+	actual, err := ra.Path()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual.String() != "objects[0].val" {
+		t.Fatalf("unexpected path %s", actual)
+	}
+
+	ra.Attribute = []json.RawMessage{[]byte(`{}`)}
+	if _, err := ra.Path(); err == nil {
+		t.Fatal("expected error for object step")
+	}
+}
+
+func TestAttributePathCtyPath(t *testing.T) {
+	path := AttributePath{AttributeStep("foo"), IndexStep(2), KeyStep("k")}
+	expected := cty.GetAttrPath("foo").IndexInt(2).IndexString("k")
+
+	actual := path.CtyPath()
+	if !actual.Equals(expected) {
+		t.Fatalf("expected %#v, got %#v", expected, actual)
+	}
+
+	back, err := AttributePathFromCtyPath(actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !back.Equal(path) {
+		t.Fatalf("expected %#v, got %#v", path, back)
+	}
+
+	invalid := []cty.Path{
+		{cty.IndexStep{Key: cty.UnknownVal(cty.String)}},
+		{cty.IndexStep{Key: cty.NumberFloatVal(1.5)}},
+		{cty.IndexStep{Key: cty.True}},
+	}
+	for _, p := range invalid {
+		if _, err := AttributePathFromCtyPath(p); err == nil {
+			t.Errorf("expected error converting %#v", p)
+		}
+	}
+}
+
+func TestAttributePathHasPrefix(t *testing.T) {
+	path := AttributePath{AttributeStep("tags"), KeyStep("env")}
+
+	if !path.HasPrefix(AttributePath{AttributeStep("tags")}) {
+		t.Fatal("expected tags to be a prefix")
+	}
+	if !path.HasPrefix(AttributePath{AttributeStep("tags"), AttributeStep("env")}) {
+		t.Fatal("expected prefix to ignore the difference between attributes and keys")
+	}
+	if path.HasPrefix(AttributePath{AttributeStep("tag")}) {
+		t.Fatal("expected tag not to be a prefix")
+	}
+}
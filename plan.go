@@ -4,7 +4,6 @@
 package tfjson
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -30,11 +29,10 @@ const (
 
 // Plan represents the entire contents of an output Terraform plan.
 type Plan struct {
-	// useJSONNumber opts into the behavior of calling
-	// json.Decoder.UseNumber prior to decoding the plan, which turns
-	// numbers into json.Numbers instead of float64s. Set it using
-	// Plan.UseJSONNumber.
-	useJSONNumber bool
+	// decodeOptions controls how the plan is decoded, including its
+	// prior state and configuration. Set it using Plan.SetDecodeOptions
+	// or Plan.UseJSONNumber.
+	decodeOptions DecodeOptions
 
 	// The version of the plan format. This should always match the
 	// PlanFormatVersion constant in this package, or else an unmarshal
@@ -107,8 +105,20 @@ type ResourceAttribute struct {
 // json.Number behavior or the float64 behavior. When b is true, the Plan will
 // represent numbers in PlanOutputs as json.Numbers. When b is false, the
 // Plan will represent numbers in PlanOutputs as float64s.
+//
+// The setting also applies to the prior state and configuration of the
+// plan. It is a shorthand for setting DecodeOptions.Numbers.
 func (p *Plan) UseJSONNumber(b bool) {
-	p.useJSONNumber = b
+	p.decodeOptions.Numbers = NumberFloat64
+	if b {
+		p.decodeOptions.Numbers = NumberJSONNumber
+	}
+}
+
+// SetDecodeOptions sets the options used when the Plan is decoded. They
+// apply to every section of the plan, including PriorState and Config.
+func (p *Plan) SetDecodeOptions(opts DecodeOptions) {
+	p.decodeOptions = opts
 }
 
 // Validate checks to ensure that the plan is present, and the
@@ -151,18 +161,40 @@ func isStringInSlice(slice []string, s string) bool {
 
 func (p *Plan) UnmarshalJSON(b []byte) error {
 	type rawPlan Plan
-	var plan rawPlan
+	var plan struct {
+		rawPlan
 
-	dec := json.NewDecoder(bytes.NewReader(b))
-	if p.useJSONNumber {
-		dec.UseNumber()
+		// PriorState and Config have their own unmarshalers, which
+		// would not see the decode options of the plan.
+		PriorState json.RawMessage `json:"prior_state,omitempty"`
+		Config     json.RawMessage `json:"configuration,omitempty"`
 	}
-	err := dec.Decode(&plan)
-	if err != nil {
+
+	opts := p.decodeOptions
+	if err := opts.decode(b, &plan); err != nil {
 		return err
 	}
+	if opts.Numbers == NumberBigFloat {
+		toBigFloats(&plan.rawPlan)
+	}
+
+	if !isJSONNull(plan.PriorState) {
+		state := &State{decodeOptions: opts}
+		if err := state.UnmarshalJSON(plan.PriorState); err != nil {
+			return err
+		}
+		plan.rawPlan.PriorState = state
+	}
+	if !isJSONNull(plan.Config) {
+		config := &Config{decodeOptions: opts}
+		if err := config.UnmarshalJSON(plan.Config); err != nil {
+			return err
+		}
+		plan.rawPlan.Config = config
+	}
 
-	*p = *(*Plan)(&plan)
+	*p = Plan(plan.rawPlan)
+	p.decodeOptions = opts
 
 	return p.Validate()
 }
@@ -211,6 +243,19 @@ type ResourceChange struct {
 
 	// The data describing the change that will be made to this object.
 	Change *Change `json:"change,omitempty"`
+
+	// ActionReason is an optional hint about why the actions were
+	// chosen, ie: ActionReasonReplaceBecauseTainted. It is empty when
+	// no special reason applies or Terraform did not record one.
+	ActionReason ActionReason `json:"action_reason,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler for ResourceChange.
+func (rc *ResourceChange) MarshalJSON() ([]byte, error) {
+	type rawResourceChange ResourceChange
+	raw := rawResourceChange(*rc)
+	raw.Index = jsonNumbers(raw.Index)
+	return json.Marshal(&raw)
 }
 
 // Change is the representation of a proposed change for an object.
@@ -268,6 +313,16 @@ type Change struct {
 	ReplacePaths []interface{} `json:"replace_paths,omitempty"`
 }
 
+// MarshalJSON implements json.Marshaler for Change.
+func (c *Change) MarshalJSON() ([]byte, error) {
+	type rawChange Change
+	raw := rawChange(*c)
+	raw.Before = jsonNumbers(raw.Before)
+	raw.After = jsonNumbers(raw.After)
+	raw.ReplacePaths, _ = jsonNumbers(raw.ReplacePaths).([]interface{})
+	return json.Marshal(&raw)
+}
+
 // Importing is a nested object for the resource import metadata.
 type Importing struct {
 	// The original ID of this resource used to target it as part of planned
@@ -281,6 +336,14 @@ type PlanVariable struct {
 	Value interface{} `json:"value,omitempty"`
 }
 
+// MarshalJSON implements json.Marshaler for PlanVariable.
+func (v *PlanVariable) MarshalJSON() ([]byte, error) {
+	type rawPlanVariable PlanVariable
+	raw := rawPlanVariable(*v)
+	raw.Value = jsonNumbers(raw.Value)
+	return json.Marshal(&raw)
+}
+
 // DeferredResourceChange is a description of a resource change that has been
 // deferred for some reason.
 type DeferredResourceChange struct {
@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+// PlanIndex provides constant time lookups of the objects in a Plan by
+// their address. It is built using Plan.Index, and is not updated if
+// the plan is modified afterwards.
+//
+// Addresses passed to the lookup methods are absolute resource
+// instance addresses, ie: `module.foo[0].aws_instance.bar["baz"]`.
+// Deposed objects can be looked up by using the address form
+// `aws_instance.bar (deposed object KEY)`, see ResourceAddress.
+type PlanIndex struct {
+	resourceChanges map[string]*ResourceChange
+	resourceDrift   map[string]*ResourceChange
+	deferredChanges map[string]*DeferredResourceChange
+	plannedValues   map[string]*StateResource
+	priorState      map[string]*StateResource
+	checks          map[string]*CheckResultStatic
+	checkInstances  map[string]checkInstance
+	configResources map[string]*ConfigResource
+}
+
+// Index builds a PlanIndex from the current contents of the plan.
+func (p *Plan) Index() *PlanIndex {
+	idx := &PlanIndex{
+		resourceChanges: make(map[string]*ResourceChange),
+		resourceDrift:   make(map[string]*ResourceChange),
+		deferredChanges: make(map[string]*DeferredResourceChange),
+		plannedValues:   make(map[string]*StateResource),
+		priorState:      make(map[string]*StateResource),
+		checks:          make(map[string]*CheckResultStatic),
+		checkInstances:  make(map[string]checkInstance),
+		configResources: make(map[string]*ConfigResource),
+	}
+	if p == nil {
+		return idx
+	}
+
+	for _, rc := range p.ResourceChanges {
+		if rc != nil {
+			idx.resourceChanges[indexKey(rc.Address, rc.DeposedKey)] = rc
+		}
+	}
+
+	for _, rc := range p.ResourceDrift {
+		if rc != nil {
+			idx.resourceDrift[indexKey(rc.Address, rc.DeposedKey)] = rc
+		}
+	}
+
+	for _, dc := range p.DeferredChanges {
+		if dc != nil && dc.ResourceChange != nil {
+			idx.deferredChanges[indexKey(dc.ResourceChange.Address, dc.ResourceChange.DeposedKey)] = dc
+		}
+	}
+
+	if p.PlannedValues != nil {
+		_ = p.PlannedValues.RootModule.Walk(func(_ *StateModule, r *StateResource) error {
+			idx.plannedValues[indexKey(r.Address, r.DeposedKey)] = r
+			return nil
+		})
+	}
+
+	if p.PriorState != nil && p.PriorState.Values != nil {
+		_ = p.PriorState.Values.RootModule.Walk(func(_ *StateModule, r *StateResource) error {
+			idx.priorState[indexKey(r.Address, r.DeposedKey)] = r
+			return nil
+		})
+	}
+
+	for i := range p.Checks {
+		check := &p.Checks[i]
+		idx.checks[indexKey(check.Address.ToDisplay, "")] = check
+		for j := range check.Instances {
+			instance := &check.Instances[j]
+			idx.checkInstances[indexKey(instance.Address.ToDisplay, "")] = checkInstance{check, instance}
+		}
+	}
+
+	if p.Config != nil {
+		_ = p.Config.RootModule.Walk(func(path ModulePath, _ []*ModuleCall, r *ConfigResource) error {
+			addr := ConfigResourceAddress{Module: path, Mode: r.Mode, Type: r.Type, Name: r.Name}
+			idx.configResources[addr.String()] = r
+			return nil
+		})
+	}
+
+	return idx
+}
+
+// checkInstance pairs a check instance result with the result of its
+// containing checkable object.
+type checkInstance struct {
+	static  *CheckResultStatic
+	dynamic *CheckResultDynamic
+}
+
+// indexKey returns the normalized key for an address and optional
+// deposed key. Addresses that cannot be parsed are used verbatim.
+func indexKey(addr, deposedKey string) string {
+	parsed, err := ParseResourceAddress(addr)
+	if err != nil {
+		if deposedKey != "" {
+			return addr + deposedSuffixPrefix + deposedKey + ")"
+		}
+		return addr
+	}
+	if deposedKey != "" {
+		parsed.DeposedKey = deposedKey
+	}
+	return parsed.String()
+}
+
+// ResourceChange returns the entry of Plan.ResourceChanges for the
+// supplied address, or nil if there is none.
+func (idx *PlanIndex) ResourceChange(addr string) *ResourceChange {
+	return idx.resourceChanges[indexKey(addr, "")]
+}
+
+// ResourceDrift returns the entry of Plan.ResourceDrift for the
+// supplied address, or nil if there is none.
+func (idx *PlanIndex) ResourceDrift(addr string) *ResourceChange {
+	return idx.resourceDrift[indexKey(addr, "")]
+}
+
+// DeferredChange returns the entry of Plan.DeferredChanges for the
+// supplied address, or nil if there is none.
+func (idx *PlanIndex) DeferredChange(addr string) *DeferredResourceChange {
+	return idx.deferredChanges[indexKey(addr, "")]
+}
+
+// PlannedResource returns the resource in Plan.PlannedValues for the
+// supplied address, or nil if there is none.
+func (idx *PlanIndex) PlannedResource(addr string) *StateResource {
+	return idx.plannedValues[indexKey(addr, "")]
+}
+
+// PriorResource returns the resource in Plan.PriorState for the
+// supplied address, or nil if there is none.
+func (idx *PlanIndex) PriorResource(addr string) *StateResource {
+	return idx.priorState[indexKey(addr, "")]
+}
+
+// CheckResult returns the entry of Plan.Checks for the supplied
+// address along with the result of the matching instance. The address
+// can be either the address of an instance of the checkable object, or
+// its static address, in which case the instance result is nil unless
+// the object has a single unkeyed instance.
+func (idx *PlanIndex) CheckResult(addr string) (*CheckResultStatic, *CheckResultDynamic) {
+	key := indexKey(addr, "")
+	if instance, ok := idx.checkInstances[key]; ok {
+		return instance.static, instance.dynamic
+	}
+	return idx.checks[key], nil
+}
+
+// ConfigResource returns the resource in Plan.Config that declares the
+// resource instance at the supplied address, or nil if there is none.
+// Instance keys in the address are ignored.
+func (idx *PlanIndex) ConfigResource(addr string) *ConfigResource {
+	parsed, err := ParseResourceAddress(addr)
+	if err != nil {
+		return nil
+	}
+	return idx.configResources[parsed.ConfigAddress().String()]
+}
@@ -0,0 +1,101 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+)
+
+func TestPlanIndex(t *testing.T) {
+	plan := testLoadPlan(t, "has_checks")
+
+	idx := plan.Index()
+
+	addr := `module.files.local_file.foo["file1.txt"]`
+
+	rc := idx.ResourceChange(addr)
+	if rc == nil || rc.Address != addr {
+		t.Fatalf("expected resource change for %s, got %#v", addr, rc)
+	}
+
+	planned := idx.PlannedResource(addr)
+	if planned == nil || planned.Address != addr {
+		t.Fatalf("expected planned resource for %s, got %#v", addr, planned)
+	}
+
+	cr := idx.ConfigResource(addr)
+	if cr == nil || cr.Address != "local_file.foo" {
+		t.Fatalf("expected config resource local_file.foo, got %#v", cr)
+	}
+
+	static, instance := idx.CheckResult(addr)
+	if static == nil || instance == nil {
+		t.Fatalf("expected check result for %s", addr)
+	}
+	if static.Address.ToDisplay != "module.files.local_file.foo" {
+		t.Fatalf("unexpected static check address %q", static.Address.ToDisplay)
+	}
+	if instance.Address.InstanceKey != "file1.txt" {
+		t.Fatalf("unexpected check instance key %v", instance.Address.InstanceKey)
+	}
+
+	static, instance = idx.CheckResult("module.files.local_file.foo")
+	if static == nil || instance != nil {
+		t.Fatalf("expected only a static check result, got %#v, %#v", static, instance)
+	}
+
+	if idx.ResourceChange("module.files.local_file.bar") != nil {
+		t.Fatal("expected no resource change for unknown address")
+	}
+	if idx.ResourceChange(addr+" (deposed object 00000001)") != nil {
+		t.Fatal("expected no resource change for deposed object")
+	}
+}
+
+func TestPlanIndex_state(t *testing.T) {
+	plan := &Plan{
+		PriorState: &State{
+			Values: &StateValues{
+				RootModule: &StateModule{
+					Resources: []*StateResource{
+						{Address: "null_resource.foo"},
+						{Address: "null_resource.foo", DeposedKey: "abcd"},
+					},
+					ChildModules: []*StateModule{
+						{
+							Address: `module.child["a"]`,
+							Resources: []*StateResource{
+								{Address: `module.child["a"].null_resource.bar[0]`},
+							},
+						},
+					},
+				},
+			},
+		},
+		ResourceDrift: []*ResourceChange{
+			{Address: "null_resource.foo", DeposedKey: "abcd"},
+		},
+		DeferredChanges: []*DeferredResourceChange{
+			{Reason: "provider_config_unknown", ResourceChange: &ResourceChange{Address: "null_resource.baz"}},
+		},
+	}
+
+	idx := plan.Index()
+
+	if r := idx.PriorResource("null_resource.foo"); r == nil || r.DeposedKey != "" {
+		t.Fatalf("expected current object, got %#v", r)
+	}
+	if r := idx.PriorResource("null_resource.foo (deposed object abcd)"); r == nil || r.DeposedKey != "abcd" {
+		t.Fatalf("expected deposed object, got %#v", r)
+	}
+	if r := idx.PriorResource(`module.child["a"].null_resource.bar[0]`); r == nil {
+		t.Fatal("expected resource in child module")
+	}
+	if rc := idx.ResourceDrift("null_resource.foo (deposed object abcd)"); rc == nil {
+		t.Fatal("expected drift for deposed object")
+	}
+	if dc := idx.DeferredChange("null_resource.baz"); dc == nil || dc.Reason != "provider_config_unknown" {
+		t.Fatalf("unexpected deferred change %#v", dc)
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import "fmt"
+
+// PlanSummary counts the changes in a plan, using the same rules as
+// the "Plan: X to add, Y to change, Z to destroy." line printed by
+// Terraform.
+type PlanSummary struct {
+	// Add is the number of resource instances that will be created,
+	// including the create half of replacements.
+	Add int
+
+	// Change is the number of resource instances that will be updated
+	// in-place.
+	Change int
+
+	// Destroy is the number of resource instances that will be
+	// destroyed, including the destroy half of replacements.
+	Destroy int
+
+	// Replace is the number of resource instances that will be
+	// replaced, in either order. Each replacement is also counted once
+	// in Add and once in Destroy.
+	Replace int
+
+	// Import is the number of resource instances that will be imported.
+	Import int
+
+	// Forget is the number of resource instances that will be removed
+	// from state without being destroyed.
+	Forget int
+
+	// Move is the number of resource instances whose address changes
+	// from PreviousAddress.
+	Move int
+
+	// Read is the number of data resource instances that will be read
+	// during apply.
+	Read int
+
+	// Deferred is the number of resource changes in DeferredChanges.
+	Deferred int
+
+	// OutputChanges is the number of outputs whose value will change.
+	OutputChanges int
+}
+
+// Summary computes a PlanSummary from the ResourceChanges,
+// DeferredChanges and OutputChanges of the plan.
+func (p *Plan) Summary() PlanSummary {
+	var s PlanSummary
+	if p == nil {
+		return s
+	}
+
+	for _, rc := range p.ResourceChanges {
+		if rc == nil || rc.Change == nil {
+			continue
+		}
+		actions := rc.Change.Actions
+
+		// Terraform does not report destroying data sources, which only
+		// means they are dropped from state.
+		if actions.Delete() && rc.Mode == DataResourceMode {
+			continue
+		}
+
+		switch {
+		case actions.Create():
+			s.Add++
+		case actions.Update():
+			s.Change++
+		case actions.Delete():
+			s.Destroy++
+		case actions.Replace():
+			s.Add++
+			s.Destroy++
+			s.Replace++
+		case actions.Read():
+			s.Read++
+		case actions.Forget():
+			s.Forget++
+		}
+
+		if rc.Change.Importing != nil {
+			s.Import++
+		}
+
+		if rc.PreviousAddress != "" && rc.PreviousAddress != rc.Address {
+			s.Move++
+		}
+	}
+
+	for _, dc := range p.DeferredChanges {
+		if dc != nil {
+			s.Deferred++
+		}
+	}
+
+	for _, oc := range p.OutputChanges {
+		if oc != nil && !oc.Actions.NoOp() {
+			s.OutputChanges++
+		}
+	}
+
+	return s
+}
+
+// Empty returns true if applying the plan would make no changes, ie:
+// no resource instance is created, updated, destroyed, read,
+// imported, forgotten or moved, and no output changes.
+func (s PlanSummary) Empty() bool {
+	return s.Add == 0 &&
+		s.Change == 0 &&
+		s.Destroy == 0 &&
+		s.Import == 0 &&
+		s.Forget == 0 &&
+		s.Move == 0 &&
+		s.Read == 0 &&
+		s.OutputChanges == 0
+}
+
+// String returns the summary in the format printed by Terraform, ie:
+// "Plan: 1 to add, 0 to change, 0 to destroy.". The import and forget
+// counts are only included when they are non-zero.
+func (s PlanSummary) String() string {
+	msg := "Plan: "
+	if s.Import > 0 {
+		msg += fmt.Sprintf("%d to import, ", s.Import)
+	}
+	msg += fmt.Sprintf("%d to add, %d to change, %d to destroy", s.Add, s.Change, s.Destroy)
+	if s.Forget > 0 {
+		msg += fmt.Sprintf(", %d to forget", s.Forget)
+	}
+	return msg + "."
+}
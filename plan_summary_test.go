@@ -0,0 +1,79 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPlanSummary(t *testing.T) {
+	plan := &Plan{
+		ResourceChanges: []*ResourceChange{
+			{Address: "a.create", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionCreate}}},
+			{Address: "a.update", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionUpdate}}},
+			{Address: "a.delete", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionDelete}}},
+			{Address: "a.dbc", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionDelete, ActionCreate}}},
+			{Address: "a.cbd", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionCreate, ActionDelete}}},
+			{Address: "a.forget", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionForget}}},
+			{Address: "a.import", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionNoop}, Importing: &Importing{ID: "i-123"}}},
+			{Address: "a.moved", PreviousAddress: "a.old", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionNoop}}},
+			{Address: "a.noop", Mode: ManagedResourceMode, Change: &Change{Actions: Actions{ActionNoop}}},
+			{Address: "data.a.read", Mode: DataResourceMode, Change: &Change{Actions: Actions{ActionRead}}},
+			{Address: "data.a.delete", Mode: DataResourceMode, Change: &Change{Actions: Actions{ActionDelete}}},
+		},
+		DeferredChanges: []*DeferredResourceChange{
+			{Reason: "resource_config_unknown", ResourceChange: &ResourceChange{Address: "a.deferred"}},
+		},
+		OutputChanges: map[string]*Change{
+			"foo": {Actions: Actions{ActionNoop}},
+		},
+	}
+
+	expected := PlanSummary{
+		Add:      3,
+		Change:   1,
+		Destroy:  3,
+		Replace:  2,
+		Import:   1,
+		Forget:   1,
+		Move:     1,
+		Read:     1,
+		Deferred: 1,
+	}
+
+	actual := plan.Summary()
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected summary: %s", diff)
+	}
+
+	if actual.Empty() {
+		t.Fatal("expected summary not to be empty")
+	}
+
+	msg := "Plan: 1 to import, 3 to add, 1 to change, 3 to destroy, 1 to forget."
+	if actual.String() != msg {
+		t.Fatalf("expected %q, got %q", msg, actual.String())
+	}
+}
+
+func TestPlanSummary_noChanges(t *testing.T) {
+	plan := testLoadPlan(t, "no_changes")
+
+	// Terraform 0.12 reported outputs as created on every plan, so only
+	// the resource counts are expected to be empty here.
+	s := plan.Summary()
+	if diff := cmp.Diff(PlanSummary{OutputChanges: 8}, s); diff != "" {
+		t.Fatalf("unexpected summary: %s", diff)
+	}
+
+	s.OutputChanges = 0
+	if !s.Empty() {
+		t.Fatalf("expected empty summary, got %#v", s)
+	}
+	if s.String() != "Plan: 0 to add, 0 to change, 0 to destroy." {
+		t.Fatalf("unexpected message %q", s.String())
+	}
+}
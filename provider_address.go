@@ -0,0 +1,166 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultProviderRegistryHost is the hostname of the public
+	// Terraform registry, which is implied when a provider source
+	// address omits its hostname.
+	DefaultProviderRegistryHost = "registry.terraform.io"
+
+	// OpenTofuProviderRegistryHost is the hostname of the public
+	// OpenTofu registry, which mirrors the providers published on
+	// DefaultProviderRegistryHost.
+	OpenTofuProviderRegistryHost = "registry.opentofu.org"
+
+	// DefaultProviderNamespace is the namespace implied for providers
+	// given only by their type, ie: "aws".
+	DefaultProviderNamespace = "hashicorp"
+
+	// LegacyProviderNamespace is the namespace used by Terraform 0.12
+	// and earlier for providers that were not yet qualified with a
+	// namespace, ie: "-/aws".
+	LegacyProviderNamespace = "-"
+)
+
+// ProviderAddress is the parsed form of a provider source address,
+// such as those found in ProviderConfig.FullName,
+// ResourceChange.ProviderName and the keys of ProviderSchemas.Schemas.
+type ProviderAddress struct {
+	// The hostname of the registry the provider is published on, ie:
+	// "registry.terraform.io".
+	Hostname string
+
+	// The namespace of the provider, ie: "hashicorp". This is
+	// LegacyProviderNamespace for legacy provider addresses.
+	Namespace string
+
+	// The type of the provider, ie: "aws".
+	Type string
+}
+
+// ParseProviderAddress parses a provider source address. It accepts
+// the following forms, with all parts normalized to lower case:
+//
+// * "hostname/namespace/type", ie: "registry.opentofu.org/hashicorp/aws"
+// * "namespace/type", implying DefaultProviderRegistryHost
+// * "-/type", a legacy address using LegacyProviderNamespace
+// * "type", implying DefaultProviderRegistryHost and
+// DefaultProviderNamespace
+func ParseProviderAddress(s string) (ProviderAddress, error) {
+	parts := strings.Split(strings.ToLower(s), "/")
+	for _, part := range parts {
+		if part == "" || strings.ContainsAny(part, " \t\n") {
+			return ProviderAddress{}, fmt.Errorf("invalid provider address %q", s)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return ProviderAddress{
+			Hostname:  DefaultProviderRegistryHost,
+			Namespace: DefaultProviderNamespace,
+			Type:      parts[0],
+		}, nil
+	case 2:
+		return ProviderAddress{
+			Hostname:  DefaultProviderRegistryHost,
+			Namespace: parts[0],
+			Type:      parts[1],
+		}, nil
+	case 3:
+		if parts[1] == LegacyProviderNamespace {
+			return ProviderAddress{}, fmt.Errorf("invalid provider address %q: legacy namespace cannot be used with a hostname", s)
+		}
+		return ProviderAddress{
+			Hostname:  parts[0],
+			Namespace: parts[1],
+			Type:      parts[2],
+		}, nil
+	}
+
+	return ProviderAddress{}, fmt.Errorf("invalid provider address %q: too many parts", s)
+}
+
+// String returns the fully-qualified form of the address, ie:
+// "registry.terraform.io/hashicorp/aws". Legacy addresses are returned
+// in their "-/aws" form.
+func (p ProviderAddress) String() string {
+	if p.IsLegacy() {
+		return p.Namespace + "/" + p.Type
+	}
+	return p.Hostname + "/" + p.Namespace + "/" + p.Type
+}
+
+// ForDisplay returns the address with the hostname omitted if it is
+// DefaultProviderRegistryHost, ie: "hashicorp/aws".
+func (p ProviderAddress) ForDisplay() string {
+	if p.Hostname == DefaultProviderRegistryHost || p.IsLegacy() {
+		return p.Namespace + "/" + p.Type
+	}
+	return p.String()
+}
+
+// IsLegacy returns true if the address uses LegacyProviderNamespace.
+func (p ProviderAddress) IsLegacy() bool {
+	return p.Namespace == LegacyProviderNamespace
+}
+
+// IsZero returns true if p is the zero value.
+func (p ProviderAddress) IsZero() bool {
+	return p == ProviderAddress{}
+}
+
+// Equal returns true if both addresses are identical.
+func (p ProviderAddress) Equal(other ProviderAddress) bool {
+	return p == other
+}
+
+// Canonical returns the address with the public registry hostnames and
+// legacy namespace normalized: OpenTofuProviderRegistryHost is
+// replaced by DefaultProviderRegistryHost, and LegacyProviderNamespace
+// by DefaultProviderNamespace, as the public registries serve the same
+// providers and legacy addresses always referred to them.
+func (p ProviderAddress) Canonical() ProviderAddress {
+	if p.Hostname == OpenTofuProviderRegistryHost {
+		p.Hostname = DefaultProviderRegistryHost
+	}
+	if p.IsLegacy() {
+		p.Hostname = DefaultProviderRegistryHost
+		p.Namespace = DefaultProviderNamespace
+	}
+	return p
+}
+
+// Equivalent returns true if both addresses refer to the same provider
+// once normalized with Canonical. This allows matching a plan produced
+// by Terraform against schemas produced by OpenTofu, and vice versa.
+func (p ProviderAddress) Equivalent(other ProviderAddress) bool {
+	return p.Canonical() == other.Canonical()
+}
+
+// ProviderAddress parses the FullName of the provider configuration.
+// If FullName is unset, as is the case for plans created by older
+// versions of Terraform, Name is parsed instead.
+func (pc *ProviderConfig) ProviderAddress() (ProviderAddress, error) {
+	if pc.FullName != "" {
+		return ParseProviderAddress(pc.FullName)
+	}
+	return ParseProviderAddress(pc.Name)
+}
+
+// ProviderAddress parses the ProviderName of the resource change.
+func (rc *ResourceChange) ProviderAddress() (ProviderAddress, error) {
+	return ParseProviderAddress(rc.ProviderName)
+}
+
+// ProviderAddress parses the ProviderName of the state resource.
+func (r *StateResource) ProviderAddress() (ProviderAddress, error) {
+	return ParseProviderAddress(r.ProviderName)
+}
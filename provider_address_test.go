@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"testing"
+)
+
+func TestParseProviderAddress(t *testing.T) {
+	cases := []struct {
+		in        string
+		expected  ProviderAddress
+		str       string
+		canonical string
+	}{
+		{
+			in:        "registry.terraform.io/hashicorp/aws",
+			expected:  ProviderAddress{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+			str:       "registry.terraform.io/hashicorp/aws",
+			canonical: "registry.terraform.io/hashicorp/aws",
+		},
+		{
+			in:        "registry.opentofu.org/hashicorp/aws",
+			expected:  ProviderAddress{Hostname: "registry.opentofu.org", Namespace: "hashicorp", Type: "aws"},
+			str:       "registry.opentofu.org/hashicorp/aws",
+			canonical: "registry.terraform.io/hashicorp/aws",
+		},
+		{
+			in:        "Integrations/GitHub",
+			expected:  ProviderAddress{Hostname: "registry.terraform.io", Namespace: "integrations", Type: "github"},
+			str:       "registry.terraform.io/integrations/github",
+			canonical: "registry.terraform.io/integrations/github",
+		},
+		{
+			in:        "-/aws",
+			expected:  ProviderAddress{Hostname: "registry.terraform.io", Namespace: "-", Type: "aws"},
+			str:       "-/aws",
+			canonical: "registry.terraform.io/hashicorp/aws",
+		},
+		{
+			in:        "aws",
+			expected:  ProviderAddress{Hostname: "registry.terraform.io", Namespace: "hashicorp", Type: "aws"},
+			str:       "registry.terraform.io/hashicorp/aws",
+			canonical: "registry.terraform.io/hashicorp/aws",
+		},
+		{
+			in:        "example.com/acme/widget",
+			expected:  ProviderAddress{Hostname: "example.com", Namespace: "acme", Type: "widget"},
+			str:       "example.com/acme/widget",
+			canonical: "example.com/acme/widget",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.in, func(t *testing.T) {
+			actual, err := ParseProviderAddress(tc.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual != tc.expected {
+				t.Fatalf("expected %#v, got %#v", tc.expected, actual)
+			}
+			if actual.String() != tc.str {
+				t.Fatalf("expected string %q, got %q", tc.str, actual.String())
+			}
+			if actual.Canonical().String() != tc.canonical {
+				t.Fatalf("expected canonical %q, got %q", tc.canonical, actual.Canonical().String())
+			}
+		})
+	}
+}
+
+func TestParseProviderAddress_invalid(t *testing.T) {
+	for _, in := range []string{"", "/aws", "hashicorp/", "a/b/c/d", "example.com/-/aws", "hashi corp/aws"} {
+		if _, err := ParseProviderAddress(in); err == nil {
+			t.Errorf("expected error parsing %q", in)
+		}
+	}
+}
+
+func TestProviderAddressEquivalent(t *testing.T) {
+	cases := []struct {
+		a, b       string
+		equal      bool
+		equivalent bool
+	}{
+		{"registry.terraform.io/hashicorp/aws", "hashicorp/aws", true, true},
+		{"registry.terraform.io/hashicorp/aws", "registry.opentofu.org/hashicorp/aws", false, true},
+		{"-/aws", "registry.opentofu.org/hashicorp/aws", false, true},
+		{"hashicorp/aws", "hashicorp/google", false, false},
+		{"example.com/hashicorp/aws", "hashicorp/aws", false, false},
+	}
+
+	for _, tc := range cases {
+		a, err := ParseProviderAddress(tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseProviderAddress(tc.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Equal(b) != tc.equal {
+			t.Errorf("%s == %s: expected %t", tc.a, tc.b, tc.equal)
+		}
+		if a.Equivalent(b) != tc.equivalent {
+			t.Errorf("%s ~= %s: expected %t", tc.a, tc.b, tc.equivalent)
+		}
+	}
+}
@@ -0,0 +1,453 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+const (
+	unknownValue   = "(known after apply)"
+	sensitiveValue = "(sensitive value)"
+)
+
+// diffAction is the change of a single value, as shown by the marker
+// in front of it.
+type diffAction int
+
+const (
+	noChange diffAction = iota
+	createValue
+	deleteValue
+	updateValue
+)
+
+func (a diffAction) symbol() string {
+	switch a {
+	case createValue:
+		return "+"
+	case deleteValue:
+		return "-"
+	case updateValue:
+		return "~"
+	}
+	return ""
+}
+
+// diffWriter writes the lines of a value diff, in the layout used by
+// Terraform: each nesting level is indented by four spaces, and lines
+// start with a three-character marker column.
+type diffWriter struct {
+	b strings.Builder
+
+	// replacePaths are the paths that force the replacement of the
+	// resource being rendered.
+	replacePaths []tfjson.AttributePath
+}
+
+func (w *diffWriter) line(depth int, symbol, format string, args ...interface{}) {
+	w.b.WriteString(strings.Repeat("    ", depth))
+	fmt.Fprintf(&w.b, "%3s ", symbol)
+	fmt.Fprintf(&w.b, format, args...)
+	w.b.WriteByte('\n')
+}
+
+func (w *diffWriter) String() string {
+	return w.b.String()
+}
+
+// replaceNote returns the comment marking path as forcing replacement,
+// if it does.
+func (w *diffWriter) replaceNote(path tfjson.AttributePath) string {
+	for _, rp := range w.replacePaths {
+		if len(path) > 0 && rp.Equal(path) {
+			return " # forces replacement"
+		}
+	}
+	return ""
+}
+
+// entry writes the change of a single value: an attribute or map
+// element when key is set, or a list element otherwise. Either side
+// is nil when absent.
+func (w *diffWriter) entry(depth int, key string, path tfjson.AttributePath, before, after *tfjson.AnnotatedValue) {
+	act := valueAction(before, after)
+	elem := key == ""
+
+	prefix, comma := key+" = ", ""
+	if elem {
+		prefix, comma = "", ","
+	}
+	note := w.replaceNote(path)
+
+	// A value changing kind cannot be diffed, so it is shown as
+	// removed and added again.
+	if act == updateValue && composite(before) && composite(after) && (before.Elements == nil) != (after.Elements == nil) {
+		w.entry(depth, key, path, before, nil)
+		w.entry(depth, key, path, nil, after)
+		return
+	}
+
+	shape := after
+	if act == deleteValue {
+		shape = before
+	}
+	expand := false
+	switch act {
+	case createValue, deleteValue:
+		expand = expandable(shape)
+	case updateValue:
+		expand = composite(before) && composite(after) && (expandable(before) || expandable(after))
+	}
+	if !expand {
+		w.line(depth, act.symbol(), "%s%s%s%s", prefix, inlineChange(act, before, after, elem), comma, note)
+		return
+	}
+
+	open, close := "{", "}"
+	if shape.Elements != nil {
+		open, close = "[", "]"
+	}
+	w.line(depth, act.symbol(), "%s%s%s", prefix, open, note)
+	if shape.Elements != nil {
+		w.elements(depth+1, path, act, before, after)
+	} else {
+		w.attributes(depth+1, path, act, before, after, false)
+	}
+	tail := ""
+	if act == deleteValue && !elem {
+		tail = " -> null"
+	}
+	w.line(depth, "", "%s%s%s", close, tail, comma)
+}
+
+// attributes writes the changes of the attributes of an object, or the
+// elements of a map, whose own change is act. Unchanged values are
+// counted rather than shown, except for the identifying attributes of
+// a resource when identifying is true.
+func (w *diffWriter) attributes(depth int, path tfjson.AttributePath, act diffAction, before, after *tfjson.AnnotatedValue, identifying bool) {
+	type child struct {
+		key           string
+		name          string
+		before, after *tfjson.AnnotatedValue
+	}
+
+	var shown []child
+	hidden, width := 0, 0
+	for _, key := range attributeKeys(before, after) {
+		c := child{key: key, name: displayKey(key), before: attribute(before, key), after: attribute(after, key)}
+		switch {
+		case valueAction(c.before, c.after) != noChange:
+			shown = append(shown, c)
+		case identifying && (key == "id" || key == "name") && !isNull(c.after) && !expandable(c.after):
+			shown = append(shown, c)
+		case (act == updateValue || act == noChange) && !isNull(c.after):
+			hidden++
+		}
+	}
+	for _, c := range shown {
+		if len(c.name) > width {
+			width = len(c.name)
+		}
+	}
+
+	for _, c := range shown {
+		name := c.name + strings.Repeat(" ", width-len(c.name))
+		w.entry(depth, name, childPath(path, tfjson.AttributeStep(c.key)), c.before, c.after)
+	}
+	if hidden > 0 {
+		w.line(depth, "", "# (%d unchanged %s hidden)", hidden, plural(hidden, "attribute"))
+	}
+}
+
+// elements writes the changes of the elements of a list whose own
+// change is act.
+func (w *diffWriter) elements(depth int, path tfjson.AttributePath, act diffAction, before, after *tfjson.AnnotatedValue) {
+	var bs, as []*tfjson.AnnotatedValue
+	if before != nil {
+		bs = before.Elements
+	}
+	if after != nil {
+		as = after.Elements
+	}
+
+	hidden := 0
+	for _, op := range listOps(bs, as) {
+		if valueAction(op.before, op.after) == noChange {
+			if act == updateValue || act == noChange {
+				hidden++
+			}
+			continue
+		}
+		w.entry(depth, "", childPath(path, tfjson.IndexStep(op.index)), op.before, op.after)
+	}
+	if hidden > 0 {
+		w.line(depth, "", "# (%d unchanged %s hidden)", hidden, plural(hidden, "element"))
+	}
+}
+
+// elemOp pairs an element of a list before the change with one after
+// it. Either is nil for removed or added elements. index is the index
+// of the element after the change, or before it for removed elements.
+type elemOp struct {
+	before, after *tfjson.AnnotatedValue
+	index         int
+}
+
+// listOps pairs the elements of two lists. Lists of primitives are
+// matched using their longest common subsequence, so that insertions
+// and removals are shown as such. Other lists are paired by index.
+func listOps(before, after []*tfjson.AnnotatedValue) []elemOp {
+	primitives := true
+	for _, v := range append(append([]*tfjson.AnnotatedValue(nil), before...), after...) {
+		if expandable(v) {
+			primitives = false
+			break
+		}
+	}
+
+	var ops []elemOp
+	if !primitives {
+		for i := 0; i < len(before) || i < len(after); i++ {
+			op := elemOp{index: i}
+			if i < len(before) {
+				op.before = before[i]
+			}
+			if i < len(after) {
+				op.after = after[i]
+			}
+			ops = append(ops, op)
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// before[i:] and after[j:].
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			switch {
+			case valuesEqual(before[i], after[j]):
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && valuesEqual(before[i], after[j]):
+			ops = append(ops, elemOp{before: before[i], after: after[j], index: j})
+			i++
+			j++
+		case j >= len(after) || (i < len(before) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, elemOp{before: before[i], index: i})
+			i++
+		default:
+			ops = append(ops, elemOp{after: after[j], index: j})
+			j++
+		}
+	}
+	return ops
+}
+
+// valueAction returns the change from before to after.
+func valueAction(before, after *tfjson.AnnotatedValue) diffAction {
+	switch {
+	case isNull(before) && isNull(after):
+		return noChange
+	case isNull(before):
+		return createValue
+	case isNull(after):
+		return deleteValue
+	case valuesEqual(before, after):
+		return noChange
+	}
+	return updateValue
+}
+
+// valuesEqual returns true if both values, along with their
+// unknown and sensitive marks, are equal.
+func valuesEqual(a, b *tfjson.AnnotatedValue) bool {
+	if isNull(a) || isNull(b) {
+		return isNull(a) && isNull(b)
+	}
+	if a.Known != b.Known || a.Sensitive != b.Sensitive {
+		return false
+	}
+	if !a.Known {
+		return true
+	}
+	if (a.Attributes != nil) != (b.Attributes != nil) || (a.Elements != nil) != (b.Elements != nil) {
+		return false
+	}
+	for _, key := range attributeKeys(a, b) {
+		if !valuesEqual(a.Attributes[key], b.Attributes[key]) {
+			return false
+		}
+	}
+	if len(a.Elements) != len(b.Elements) {
+		return false
+	}
+	for i := range a.Elements {
+		if !valuesEqual(a.Elements[i], b.Elements[i]) {
+			return false
+		}
+	}
+	return tfjson.ValuesEqual(a.Value, b.Value, nil)
+}
+
+func isNull(v *tfjson.AnnotatedValue) bool {
+	return v == nil || v.IsNull()
+}
+
+// composite returns true if v is a known and non-sensitive object, map
+// or list.
+func composite(v *tfjson.AnnotatedValue) bool {
+	return v != nil && v.Known && !v.Sensitive && (v.Attributes != nil || v.Elements != nil)
+}
+
+// expandable returns true if v is a non-empty composite value, which is
+// shown over several lines.
+func expandable(v *tfjson.AnnotatedValue) bool {
+	return composite(v) && (len(v.Attributes) > 0 || len(v.Elements) > 0)
+}
+
+// inlineChange renders a change on a single line.
+func inlineChange(act diffAction, before, after *tfjson.AnnotatedValue, elem bool) string {
+	switch act {
+	case createValue:
+		return inlineValue(after)
+	case deleteValue:
+		if elem {
+			return inlineValue(before)
+		}
+		return inlineValue(before) + " -> null"
+	case updateValue:
+		if before.Sensitive && after.Sensitive {
+			return sensitiveValue
+		}
+		return inlineValue(before) + " -> " + inlineValue(after)
+	}
+	return inlineValue(after)
+}
+
+// inlineValue renders a value on a single line.
+func inlineValue(v *tfjson.AnnotatedValue) string {
+	switch {
+	case v == nil:
+		return "null"
+	case !v.Known:
+		return unknownValue
+	case v.Sensitive:
+		return sensitiveValue
+	case v.Attributes != nil:
+		if len(v.Attributes) == 0 {
+			return "{}"
+		}
+		parts := make([]string, 0, len(v.Attributes))
+		for _, key := range attributeKeys(v, nil) {
+			parts = append(parts, displayKey(key)+" = "+inlineValue(v.Attributes[key]))
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case v.Elements != nil:
+		if len(v.Elements) == 0 {
+			return "[]"
+		}
+		parts := make([]string, 0, len(v.Elements))
+		for _, elem := range v.Elements {
+			parts = append(parts, inlineValue(elem))
+		}
+		return "[ " + strings.Join(parts, ", ") + " ]"
+	}
+	return literal(v.Value)
+}
+
+// literal renders a primitive JSON value.
+func literal(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	case *big.Float:
+		return v.Text('f', -1)
+	}
+	return fmt.Sprint(v)
+}
+
+// displayKey renders an attribute name or map key, quoting it unless
+// it is a valid identifier.
+func displayKey(key string) string {
+	for i, r := range key {
+		if !(r == '_' || unicode.IsLetter(r) || (i > 0 && (r == '-' || unicode.IsDigit(r)))) {
+			return strconv.Quote(key)
+		}
+	}
+	if key == "" {
+		return `""`
+	}
+	return key
+}
+
+// attributeKeys returns the sorted union of the attribute names of a
+// and b.
+func attributeKeys(a, b *tfjson.AnnotatedValue) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, v := range []*tfjson.AnnotatedValue{a, b} {
+		if v == nil {
+			continue
+		}
+		for key := range v.Attributes {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func attribute(v *tfjson.AnnotatedValue, key string) *tfjson.AnnotatedValue {
+	if v == nil {
+		return nil
+	}
+	return v.Attributes[key]
+}
+
+func childPath(path tfjson.AttributePath, step tfjson.AttributePathStep) tfjson.AttributePath {
+	ret := make(tfjson.AttributePath, len(path), len(path)+1)
+	copy(ret, path)
+	return append(ret, step)
+}
+
+func plural(n int, noun string) string {
+	if n == 1 {
+		return noun
+	}
+	return noun + "s"
+}
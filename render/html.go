@@ -0,0 +1,340 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+// HTML writes a self-contained HTML report of the plan to w, for
+// archiving: the summary of the plan, its resource changes in a tree of
+// modules that can be filtered by action, with an expandable attribute
+// diff for each resource, the output changes and the check results.
+// The report loads no external assets.
+//
+// If schemas is not nil, the description of each attribute is taken
+// from the schema of its resource, and attributes marked sensitive by
+// the schema are redacted along with the values marked sensitive by the
+// plan. Sensitive values are redacted before the report is rendered, so
+// they never reach the document.
+func HTML(w io.Writer, plan *tfjson.Plan, schemas *tfjson.ProviderSchemas) error {
+	if plan == nil {
+		return errors.New("plan is nil")
+	}
+	return htmlTemplate.Execute(w, newHTMLReport(plan, schemas))
+}
+
+// htmlReport holds the redacted contents of the report.
+type htmlReport struct {
+	TerraformVersion string
+	Summary          string
+	Empty            bool
+	Actions          []htmlAction
+	Root             *htmlModule
+	Outputs          []htmlAttribute
+	Checks           []htmlCheck
+}
+
+// htmlAction is an action that the resources can be filtered by.
+type htmlAction struct {
+	Name  string
+	Count int
+}
+
+type htmlModule struct {
+	Address   string
+	Resources []htmlResource
+	Modules   []*htmlModule
+}
+
+type htmlResource struct {
+	Address   string
+	Action    string
+	Symbol    string
+	Header    string
+	Notes     []string
+	Changed   []htmlAttribute
+	Unchanged []htmlAttribute
+}
+
+type htmlAttribute struct {
+	Symbol      string
+	Diff        string
+	Description string
+}
+
+type htmlCheck struct {
+	Address  string
+	Kind     string
+	Status   string
+	Problems []string
+}
+
+// htmlActions lists the actions that the resources can be filtered by,
+// in the order they are offered.
+var htmlActions = []string{"create", "update", "replace", "delete", "read", "import", "move", "forget"}
+
+func newHTMLReport(plan *tfjson.Plan, schemas *tfjson.ProviderSchemas) *htmlReport {
+	summary := plan.Summary()
+	report := &htmlReport{
+		TerraformVersion: plan.TerraformVersion,
+		Summary:          summary.String(),
+		Empty:            summary.Empty(),
+		Root:             &htmlModule{},
+	}
+
+	counts := make(map[string]int)
+	modules := map[string]*htmlModule{"": report.Root}
+	for _, rc := range displayedChanges(plan) {
+		r := newHTMLResource(rc, schemas)
+		counts[r.Action]++
+		m := htmlModuleAt(modules, rc.ModuleAddress)
+		m.Resources = append(m.Resources, r)
+	}
+	for _, action := range htmlActions {
+		if counts[action] > 0 {
+			report.Actions = append(report.Actions, htmlAction{Name: action, Count: counts[action]})
+		}
+	}
+
+	var names []string
+	for name, oc := range plan.OutputChanges {
+		if oc != nil && !oc.Actions.NoOp() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oc := plan.OutputChanges[name]
+		report.Outputs = append(report.Outputs, newHTMLAttribute(name, nil, oc.BeforeAnnotated(), oc.AfterAnnotated(), nil, ""))
+	}
+
+	for _, check := range plan.Checks {
+		if len(check.Instances) == 0 {
+			report.Checks = append(report.Checks, htmlCheck{
+				Address: check.Address.ToDisplay,
+				Kind:    string(check.Address.Kind),
+				Status:  string(check.Status),
+			})
+		}
+		for _, instance := range check.Instances {
+			c := htmlCheck{
+				Address: instance.Address.ToDisplay,
+				Kind:    string(check.Address.Kind),
+				Status:  string(instance.Status),
+			}
+			for _, problem := range instance.Problems {
+				c.Problems = append(c.Problems, problem.Message)
+			}
+			report.Checks = append(report.Checks, c)
+		}
+	}
+
+	return report
+}
+
+// htmlModuleAt returns the node of the module tree for the module
+// instance at addr, creating it and its parents as needed.
+func htmlModuleAt(modules map[string]*htmlModule, addr string) *htmlModule {
+	if m, ok := modules[addr]; ok {
+		return m
+	}
+
+	parent := modules[""]
+	if path, err := tfjson.ParseModuleInstancePath(addr); err == nil && len(path) > 1 {
+		parent = htmlModuleAt(modules, path[:len(path)-1].String())
+	}
+	m := &htmlModule{Address: addr}
+	parent.Modules = append(parent.Modules, m)
+	modules[addr] = m
+	return m
+}
+
+// htmlActionName returns the action that a resource change is filtered
+// by.
+func htmlActionName(rc *tfjson.ResourceChange) string {
+	actions := rc.Change.Actions
+	switch {
+	case actions.Replace():
+		return "replace"
+	case actions.Create():
+		return "create"
+	case actions.Update():
+		return "update"
+	case actions.Delete():
+		return "delete"
+	case actions.Read():
+		return "read"
+	case actions.Forget():
+		return "forget"
+	case rc.Change.Importing != nil:
+		return "import"
+	}
+	return "move"
+}
+
+func newHTMLResource(rc *tfjson.ResourceChange, schemas *tfjson.ProviderSchemas) htmlResource {
+	comments := resourceComments(rc, false)
+	r := htmlResource{
+		Address: rc.Address,
+		Action:  htmlActionName(rc),
+		Symbol:  actionSymbol(rc.Change.Actions),
+		Header:  strings.TrimPrefix(comments[0], rc.Address+" "),
+		Notes:   comments[1:],
+	}
+
+	var block *tfjson.SchemaBlock
+	if schemas != nil {
+		if schema, err := schemas.ResourceChangeSchema(rc); err == nil {
+			block = schema.Block
+		}
+	}
+	replacePaths, _ := rc.Change.ReplaceAttributePaths()
+
+	before, after := rc.Change.BeforeAnnotated(), rc.Change.AfterAnnotated()
+	if rc.Change.Actions.Forget() {
+		after = before
+	}
+	for _, name := range attributeKeys(before, after) {
+		b, a := attribute(before, name), attribute(after, name)
+		description, sensitive := schemaAttribute(block, name)
+		act := valueAction(b, a)
+		if sensitive {
+			b, a = redacted(b), redacted(a)
+		}
+		attr := newHTMLAttribute(name, replacePaths, b, a, tfjson.AttributePath{tfjson.AttributeStep(name)}, description)
+		if sensitive && act == updateValue {
+			// Both redacted values look the same, but the change must
+			// still be shown.
+			attr.Symbol = act.symbol()
+			attr.Diff = fmt.Sprintf("%3s %s = %s\n", attr.Symbol, displayKey(name), sensitiveValue)
+		}
+		switch {
+		case attr.Symbol != "":
+			r.Changed = append(r.Changed, attr)
+		case !isNull(a):
+			r.Unchanged = append(r.Unchanged, attr)
+		}
+	}
+	return r
+}
+
+// newHTMLAttribute renders the diff of a single attribute or output.
+func newHTMLAttribute(name string, replacePaths []tfjson.AttributePath, before, after *tfjson.AnnotatedValue, path tfjson.AttributePath, description string) htmlAttribute {
+	w := &diffWriter{replacePaths: replacePaths}
+	w.entry(0, displayKey(name), path, before, after)
+	return htmlAttribute{
+		Symbol:      valueAction(before, after).symbol(),
+		Diff:        w.String(),
+		Description: description,
+	}
+}
+
+// schemaAttribute returns the description of the attribute or nested
+// block called name in block, and whether the schema marks it as
+// sensitive.
+func schemaAttribute(block *tfjson.SchemaBlock, name string) (string, bool) {
+	if block == nil {
+		return "", false
+	}
+	if attr, ok := block.Attributes[name]; ok && attr != nil {
+		return attr.Description, attr.Sensitive
+	}
+	if nested, ok := block.NestedBlocks[name]; ok && nested != nil && nested.Block != nil {
+		return nested.Block.Description, false
+	}
+	return "", false
+}
+
+// redacted returns v marked as sensitive, unless it is null.
+func redacted(v *tfjson.AnnotatedValue) *tfjson.AnnotatedValue {
+	if isNull(v) {
+		return v
+	}
+	return &tfjson.AnnotatedValue{Known: v.Known, Sensitive: true}
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Terraform plan report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em; color: #1f2328; }
+code, pre { font-family: ui-monospace, Menlo, Consolas, monospace; font-size: 13px; }
+pre { margin: 0; white-space: pre-wrap; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d0d7de; padding: 4px 8px; text-align: left; vertical-align: top; }
+details { margin: 4px 0; }
+summary { cursor: pointer; }
+.module { margin-left: 1.5em; }
+.resource { margin-left: 1em; }
+.notes { color: #656d76; margin: 2px 0 2px 1.5em; }
+.attribute { margin: 4px 0 4px 1.5em; }
+.description { color: #656d76; margin: 0 0 0 3.5em; font-size: 13px; }
+.sym { display: inline-block; width: 2.5em; font-family: ui-monospace, Menlo, Consolas, monospace; font-weight: bold; }
+.create .sym { color: #1a7f37; }
+.update .sym { color: #9a6700; }
+.replace .sym, .delete .sym { color: #cf222e; }
+.status-pass { color: #1a7f37; }
+.status-fail, .status-error { color: #cf222e; }
+.status-unknown { color: #656d76; }
+</style>
+</head>
+<body>
+<h1>Terraform plan report</h1>
+{{if .TerraformVersion}}<p>Terraform version: <code>{{.TerraformVersion}}</code></p>
+{{end}}{{if .Empty}}<p>No changes. Your infrastructure matches the configuration.</p>
+{{else}}<p><strong>{{.Summary}}</strong></p>
+{{end}}{{if .Actions}}
+<h2>Resource changes</h2>
+<p id="filters">{{range .Actions}}
+<label><input type="checkbox" value="{{.Name}}" checked> {{.Name}} ({{.Count}})</label>{{end}}
+</p>
+{{template "module" .Root}}{{end}}{{if .Outputs}}
+<h2>Changes to outputs</h2>
+{{range .Outputs}}{{template "attribute" .}}{{end}}{{end}}{{if .Checks}}
+<h2>Checks</h2>
+<table>
+<tr><th>Check</th><th>Kind</th><th>Status</th><th>Problems</th></tr>
+{{range .Checks}}<tr><td><code>{{.Address}}</code></td><td>{{.Kind}}</td><td class="status-{{.Status}}">{{.Status}}</td><td>{{range .Problems}}<div>{{.}}</div>{{end}}</td></tr>
+{{end}}</table>
+{{end}}<script>
+(function () {
+  var inputs = document.querySelectorAll("#filters input");
+  function apply() {
+    var shown = {};
+    inputs.forEach(function (input) { shown[input.value] = input.checked; });
+    document.querySelectorAll(".resource").forEach(function (el) {
+      el.hidden = !shown[el.getAttribute("data-action")];
+    });
+    document.querySelectorAll(".module").forEach(function (el) {
+      el.hidden = !el.querySelector(".resource:not([hidden])");
+    });
+  }
+  inputs.forEach(function (input) { input.addEventListener("change", apply); });
+})();
+</script>
+</body>
+</html>
+{{define "module"}}{{if .Address}}<details class="module" open>
+<summary><code>{{.Address}}</code></summary>
+{{end}}{{range .Resources}}<details class="resource {{.Action}}" data-action="{{.Action}}">
+<summary><span class="sym">{{.Symbol}}</span><code>{{.Address}}</code> {{.Header}}</summary>
+{{range .Notes}}<div class="notes">{{.}}</div>
+{{end}}{{range .Changed}}{{template "attribute" .}}{{end}}{{if .Unchanged}}<details class="attribute">
+<summary>{{len .Unchanged}} unchanged {{if eq (len .Unchanged) 1}}attribute{{else}}attributes{{end}}</summary>
+{{range .Unchanged}}{{template "attribute" .}}{{end}}</details>
+{{end}}</details>
+{{end}}{{range .Modules}}{{template "module" .}}{{end}}{{if .Address}}</details>
+{{end}}{{end}}
+{{define "attribute"}}<div class="attribute"><pre>{{.Diff}}</pre>{{if .Description}}<p class="description">{{.Description}}</p>{{end}}</div>
+{{end}}`))
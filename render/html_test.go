@@ -0,0 +1,111 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+// testSchemas lists the provider schemas of the plans in testPlans
+// that have them.
+var testSchemas = map[string]string{
+	"basic":       filepath.Join("..", "testdata", "basic", "schemas.json"),
+	"has_changes": filepath.Join("..", "testdata", "has_changes", "schemas.json"),
+}
+
+func testLoadSchemas(t *testing.T, path string) *tfjson.ProviderSchemas {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := new(tfjson.ProviderSchemas)
+	if err := json.Unmarshal(b, schemas); err != nil {
+		t.Fatal(err)
+	}
+	return schemas
+}
+
+func TestHTMLNil(t *testing.T) {
+	if err := HTML(new(bytes.Buffer), nil, nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestHTMLGolden(t *testing.T) {
+	for name, path := range testPlans {
+		t.Run(name, func(t *testing.T) {
+			plan := testLoadPlan(t, path)
+
+			var schemas *tfjson.ProviderSchemas
+			if schemasPath, ok := testSchemas[name]; ok {
+				schemas = testLoadSchemas(t, schemasPath)
+			}
+
+			var out bytes.Buffer
+			if err := HTML(&out, plan, schemas); err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range []string{"hunter2", "hunter3", "s3cr3t"} {
+				if strings.Contains(out.String(), secret) {
+					t.Fatalf("report contains sensitive value %q", secret)
+				}
+			}
+			for _, ref := range []string{"src=", "href=", "@import", "url("} {
+				if strings.Contains(out.String(), ref) {
+					t.Fatalf("report refers to an external asset with %q", ref)
+				}
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".html.golden"))
+			g.Assert(t, name, out.Bytes())
+		})
+	}
+}
+
+func TestHTMLSchemaSensitive(t *testing.T) {
+	plan := testLoadPlan(t, filepath.Join(testDataDir, "changes.json"))
+	schemas := &tfjson.ProviderSchemas{
+		FormatVersion: "1.0",
+		Schemas: map[string]*tfjson.ProviderSchema{
+			"registry.terraform.io/hashicorp/aws": {
+				ResourceSchemas: map[string]*tfjson.Schema{
+					"aws_instance": {
+						Block: &tfjson.SchemaBlock{
+							Attributes: map[string]*tfjson.SchemaAttribute{
+								"instance_type": {Description: "The type of the instance.", Sensitive: true},
+								"user_data":     {Description: "The user data to provide.", Sensitive: true},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var out bytes.Buffer
+	if err := HTML(&out, plan, schemas); err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"t3.small", "#!/bin/sh"} {
+		if strings.Contains(out.String(), secret) {
+			t.Fatalf("report contains sensitive value %q", secret)
+		}
+	}
+	for _, want := range []string{"~ instance_type = (sensitive value)", "The type of the instance."} {
+		if !strings.Contains(out.String(), want) {
+			t.Fatalf("report does not contain %q", want)
+		}
+	}
+}
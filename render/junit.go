@@ -0,0 +1,144 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+// junitTestSuites is the root element of a JUnit XML report.
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// JUnit writes the results of checks, as found in Plan.Checks or
+// State.Checks, to w as a JUnit XML report.
+//
+// The report holds a test suite for each CheckKind, in the order they
+// first appear, and a test case for each CheckResultDynamic, named after
+// its address and classed under the address of its CheckResultStatic. A
+// CheckResultStatic without instances, such as a resource whose count is
+// not known yet, gets a single test case named after its own address.
+//
+// Failed checks are reported as failures carrying the messages of their
+// problems, checks that could not be evaluated as errors, and checks
+// whose status is unknown as skipped.
+func JUnit(w io.Writer, checks []tfjson.CheckResultStatic) error {
+	report := junitTestSuites{Name: "terraform checks"}
+	index := make(map[tfjson.CheckKind]int)
+	for _, check := range checks {
+		i, ok := index[check.Address.Kind]
+		if !ok {
+			i = len(report.Suites)
+			index[check.Address.Kind] = i
+			report.Suites = append(report.Suites, junitTestSuite{Name: string(check.Address.Kind)})
+		}
+		suite := &report.Suites[i]
+
+		if len(check.Instances) == 0 {
+			suite.add(newJUnitTestCase(check.Address.ToDisplay, check.Address.ToDisplay, check.Status, nil))
+		}
+		for _, instance := range check.Instances {
+			suite.add(newJUnitTestCase(instance.Address.ToDisplay, check.Address.ToDisplay, instance.Status, instance.Problems))
+		}
+	}
+
+	for _, suite := range report.Suites {
+		report.Tests += suite.Tests
+		report.Failures += suite.Failures
+		report.Errors += suite.Errors
+		report.Skipped += suite.Skipped
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func (s *junitTestSuite) add(tc junitTestCase) {
+	s.Cases = append(s.Cases, tc)
+	s.Tests++
+	switch {
+	case tc.Failure != nil:
+		s.Failures++
+	case tc.Error != nil:
+		s.Errors++
+	case tc.Skipped != nil:
+		s.Skipped++
+	}
+}
+
+func newJUnitTestCase(name, className string, status tfjson.CheckStatus, problems []tfjson.CheckResultProblem) junitTestCase {
+	tc := junitTestCase{Name: name, ClassName: className}
+	switch status {
+	case tfjson.CheckStatusFail:
+		tc.Failure = newJUnitProblem(status, problems, "the check failed")
+	case tfjson.CheckStatusError:
+		tc.Error = newJUnitProblem(status, problems, "the check could not be evaluated")
+	case tfjson.CheckStatusUnknown:
+		tc.Skipped = &junitSkipped{Message: "the result of the check is not known yet"}
+	}
+	return tc
+}
+
+// newJUnitProblem describes a failed check with the messages of its
+// problems, the first of them serving as the summary.
+func newJUnitProblem(status tfjson.CheckStatus, problems []tfjson.CheckResultProblem, fallback string) *junitProblem {
+	p := &junitProblem{Message: fallback, Type: string(status)}
+	if len(problems) == 0 {
+		return p
+	}
+
+	messages := make([]string, len(problems))
+	for i, problem := range problems {
+		messages[i] = problem.Message
+	}
+	p.Message = messages[0]
+	p.Text = strings.Join(messages, "\n")
+	return p
+}
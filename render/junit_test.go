@@ -0,0 +1,51 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+func TestJUnitGolden(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join("..", "testdata", "has_checks", "state.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := new(tfjson.State)
+	if err := json.Unmarshal(b, state); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]tfjson.CheckResultStatic{
+		"checks":           testLoadPlan(t, filepath.Join(testDataDir, "checks.json")).Checks,
+		"has_checks_plan":  testLoadPlan(t, filepath.Join("..", "testdata", "has_checks", "plan.json")).Checks,
+		"has_checks_state": state.Checks,
+		"empty":            nil,
+	}
+	for name, checks := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := JUnit(&out, checks); err != nil {
+				t.Fatal(err)
+			}
+
+			var report junitTestSuites
+			if err := xml.Unmarshal(out.Bytes(), &report); err != nil {
+				t.Fatalf("report is not valid XML: %s", err)
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".junit.golden"))
+			g.Assert(t, name, out.Bytes())
+		})
+	}
+}
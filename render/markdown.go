@@ -0,0 +1,367 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+// MarkdownOptions controls how Markdown renders a plan.
+type MarkdownOptions struct {
+	// MaxLength is the maximum length of the document in bytes, such as
+	// the 65536 characters accepted in a GitHub comment. When the whole
+	// document does not fit, the attribute diffs are left out first,
+	// then the resources that still do not fit, starting from the last
+	// ones, and a note tells how many were left out. The summary table,
+	// the failing checks and the deferred changes are always kept. Zero
+	// means no limit.
+	MaxLength int
+
+	// MaxDiffLines is the maximum number of lines in the attribute diff
+	// of a single resource. Longer diffs are cut, with a note telling
+	// how many lines were left out. Zero means no limit.
+	MaxDiffLines int
+}
+
+// deferredReasons describes the reasons Terraform gives for deferring a
+// resource change.
+var deferredReasons = map[string]string{
+	"unknown":                 "unknown reason",
+	"instance_count_unknown":  "the count or for_each of the resource is not known yet",
+	"resource_config_unknown": "the configuration of the resource is not known yet",
+	"provider_config_unknown": "the configuration of the provider is not known yet",
+	"absent_prereq":           "a prerequisite of the resource does not exist yet",
+	"deferred_prereq":         "a prerequisite of the resource was deferred",
+}
+
+// Markdown writes the plan to w as a Markdown document meant for a pull
+// request comment: a summary table of the actions, the failing checks,
+// the deferred changes, then a collapsible section per module with the
+// attribute diffs of its resources, and the output changes.
+//
+// Values marked as sensitive in the plan are never written.
+func Markdown(w io.Writer, plan *tfjson.Plan, opts MarkdownOptions) error {
+	if plan == nil {
+		return errors.New("plan is nil")
+	}
+
+	head := markdownHead(plan)
+	modules := markdownModules(plan, opts)
+
+	doc := head + renderModules(modules, true, -1)
+	if opts.MaxLength > 0 && len(doc) > opts.MaxLength {
+		doc = head + renderModules(modules, false, -1)
+		if len(doc) > opts.MaxLength {
+			budget := opts.MaxLength - len(head)
+			if budget < 0 {
+				budget = 0
+			}
+			doc = head + renderModules(modules, false, budget)
+		}
+	}
+
+	_, err := io.WriteString(w, doc)
+	return err
+}
+
+// markdownHead renders the parts of the document that are never left
+// out.
+func markdownHead(plan *tfjson.Plan) string {
+	var b strings.Builder
+	summary := plan.Summary()
+
+	b.WriteString("## Terraform plan\n\n")
+	if summary.Empty() {
+		b.WriteString("No changes. Your infrastructure matches the configuration.\n")
+	} else {
+		fmt.Fprintf(&b, "**%s**\n\n", summary.String())
+		b.WriteString("| Action | Count |\n")
+		b.WriteString("| --- | ---: |\n")
+		for _, row := range []struct {
+			name  string
+			count int
+		}{
+			{"Create", summary.Add - summary.Replace},
+			{"Update", summary.Change},
+			{"Replace", summary.Replace},
+			{"Destroy", summary.Destroy - summary.Replace},
+			{"Read", summary.Read},
+			{"Import", summary.Import},
+			{"Move", summary.Move},
+			{"Forget", summary.Forget},
+			{"Deferred", summary.Deferred},
+			{"Output change", summary.OutputChanges},
+		} {
+			if row.count > 0 {
+				fmt.Fprintf(&b, "| %s | %d |\n", row.name, row.count)
+			}
+		}
+	}
+
+	writeFailingChecks(&b, plan.Checks)
+	writeDeferredChanges(&b, plan.DeferredChanges)
+	return b.String()
+}
+
+// writeFailingChecks lists the checks that failed or could not be
+// evaluated, with their problems.
+func writeFailingChecks(b *strings.Builder, checks []tfjson.CheckResultStatic) {
+	var lines []string
+	for _, check := range checks {
+		if !checkFailing(check.Status) {
+			continue
+		}
+		failing := 0
+		for _, instance := range check.Instances {
+			if !checkFailing(instance.Status) {
+				continue
+			}
+			failing++
+			lines = append(lines, fmt.Sprintf("- %s: %s", htmlCode(instance.Address.ToDisplay), instance.Status))
+			for _, problem := range instance.Problems {
+				lines = append(lines, "  - "+markdownText(problem.Message))
+			}
+		}
+		if failing == 0 {
+			lines = append(lines, fmt.Sprintf("- %s: %s", htmlCode(check.Address.ToDisplay), check.Status))
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+
+	b.WriteString("\n### Failing checks\n\n")
+	for _, line := range lines {
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+func checkFailing(status tfjson.CheckStatus) bool {
+	return status == tfjson.CheckStatusFail || status == tfjson.CheckStatusError
+}
+
+// writeDeferredChanges lists the deferred changes with the reasons they
+// were deferred.
+func writeDeferredChanges(b *strings.Builder, changes []*tfjson.DeferredResourceChange) {
+	var rows []string
+	for _, dc := range changes {
+		if dc == nil || dc.ResourceChange == nil {
+			continue
+		}
+		rc := dc.ResourceChange
+		action := ""
+		if rc.Change != nil {
+			action = actionName(rc.Change.Actions)
+		}
+		reason := htmlCode(dc.Reason)
+		if desc, ok := deferredReasons[dc.Reason]; ok {
+			reason += ": " + desc
+		}
+		rows = append(rows, fmt.Sprintf("| %s | %s | %s |", htmlCode(rc.Address), action, reason))
+	}
+	if len(rows) == 0 {
+		return
+	}
+
+	b.WriteString("\n### Deferred changes\n\n")
+	b.WriteString("| Resource | Action | Reason |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, row := range rows {
+		b.WriteString(row)
+		b.WriteString("\n")
+	}
+}
+
+// actionName names actions as the legend of the plan does.
+func actionName(actions tfjson.Actions) string {
+	for _, entry := range legendEntries {
+		if entry.match(actions) {
+			return entry.text
+		}
+	}
+	return "no-op"
+}
+
+// changeSummary describes a resource change on a single line, as the
+// first comment above its block does in the text of the plan.
+func changeSummary(rc *tfjson.ResourceChange) string {
+	header := resourceComments(rc, false)[0]
+	if desc := strings.TrimPrefix(header, rc.Address+" "); desc != header {
+		return htmlCode(rc.Address) + " " + markdownText(desc)
+	}
+	return markdownText(header)
+}
+
+// markdownSection is a collapsible section of the document, holding the
+// changes of a module or the output changes.
+type markdownSection struct {
+	title string
+	items []markdownItem
+}
+
+// markdownItem is a change shown in a section, in full with its
+// attribute diff or as a short line.
+type markdownItem struct {
+	full  string
+	short string
+}
+
+// markdownModules groups the displayed resource changes by module, in
+// the order the modules first appear, followed by the output changes.
+func markdownModules(plan *tfjson.Plan, opts MarkdownOptions) []markdownSection {
+	var sections []markdownSection
+	index := make(map[string]int)
+	for _, rc := range displayedChanges(plan) {
+		i, ok := index[rc.ModuleAddress]
+		if !ok {
+			i = len(sections)
+			index[rc.ModuleAddress] = i
+			title := "root module"
+			if rc.ModuleAddress != "" {
+				title = rc.ModuleAddress
+			}
+			sections = append(sections, markdownSection{title: title})
+		}
+		sections[i].items = append(sections[i].items, markdownItem{
+			full:  diffBlock(resourceChangeText(rc, false), opts.MaxDiffLines),
+			short: "- " + changeSummary(rc) + "\n",
+		})
+	}
+	for i := range sections {
+		sections[i].title = fmt.Sprintf("%s (%d %s)", htmlCode(sections[i].title), len(sections[i].items), plural(len(sections[i].items), "change"))
+	}
+
+	if outputs := outputChangeLines(plan); outputs != "" {
+		block := diffBlock(outputs, opts.MaxDiffLines)
+		sections = append(sections, markdownSection{
+			title: "Changes to Outputs",
+			items: []markdownItem{{full: block, short: block}},
+		})
+	}
+	return sections
+}
+
+// renderModules renders the sections, with the attribute diffs if full
+// is true. If budget is not negative, it is the maximum length of the
+// result: the output stops at the first item that does not fit, and
+// a note says how many were left out. A section is only opened if its
+// first item fits.
+func renderModules(sections []markdownSection, full bool, budget int) string {
+	const closing = "\n</details>\n"
+
+	total := 0
+	for _, s := range sections {
+		total += len(s.items)
+	}
+
+	var b strings.Builder
+	shown := 0
+sections:
+	for _, s := range sections {
+		open := fmt.Sprintf("\n<details><summary>%s</summary>\n\n", s.title)
+		for i, item := range s.items {
+			text := item.short
+			if full {
+				text = item.full
+			}
+			if i == 0 {
+				text = open + text
+			}
+			if budget >= 0 && b.Len()+len(text)+len(closing)+len(truncatedNote(total-shown-1)) > budget {
+				if i > 0 {
+					b.WriteString(closing)
+				}
+				break sections
+			}
+			b.WriteString(text)
+			shown++
+		}
+		if len(s.items) > 0 {
+			b.WriteString(closing)
+		}
+	}
+	if shown < total {
+		b.WriteString(truncatedNote(total - shown))
+	}
+	return b.String()
+}
+
+// truncatedNote tells that n changes were left out of the document.
+func truncatedNote(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n_%d more %s not shown. Run `terraform show` to see the whole plan._\n", n, plural(n, "change"))
+}
+
+// diffBlock renders text as a fenced code block highlighted as a diff,
+// moving the marker of each changed line to its first column. Lines
+// after the first maxLines are left out, if maxLines is positive.
+func diffBlock(text string, maxLines int) string {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	if maxLines > 0 && len(lines) > maxLines {
+		omitted := len(lines) - maxLines
+		lines = append(lines[:maxLines], fmt.Sprintf("  # (%d more %s not shown)", omitted, plural(omitted, "line")))
+	}
+	for i, line := range lines {
+		lines[i] = diffLine(line)
+	}
+
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "diff\n" + strings.Join(lines, "\n") + "\n" + fence + "\n"
+}
+
+// diffLine moves the marker of a changed line to its first column, as
+// the diff syntax expects: "+" and "-" are kept and the other markers
+// become "!".
+func diffLine(line string) string {
+	trimmed := strings.TrimLeft(line, " ")
+	marker := trimmed
+	if i := strings.IndexByte(trimmed, ' '); i >= 0 {
+		marker = trimmed[:i]
+	}
+
+	var mark string
+	switch marker {
+	case "+", "-":
+		mark = marker
+	case "~", "-/+", "+/-", "<=":
+		mark = "!"
+	default:
+		return line
+	}
+
+	start := len(line) - len(trimmed)
+	blanked := line[:start] + strings.Repeat(" ", len(marker)) + line[start+len(marker):]
+	return mark + blanked[1:]
+}
+
+// htmlCode renders s as inline code that is safe in HTML tags and
+// table cells.
+func htmlCode(s string) string {
+	return "<code>" + strings.ReplaceAll(html.EscapeString(s), "|", "&#124;") + "</code>"
+}
+
+// markdownText escapes s so that it renders as plain text.
+func markdownText(s string) string {
+	s = html.EscapeString(strings.Join(strings.Fields(s), " "))
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune("\\`*_[]|#", r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
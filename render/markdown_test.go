@@ -0,0 +1,127 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+)
+
+func TestMarkdownNil(t *testing.T) {
+	if err := Markdown(new(bytes.Buffer), nil, MarkdownOptions{}); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestMarkdownGolden(t *testing.T) {
+	for name, path := range testPlans {
+		t.Run(name, func(t *testing.T) {
+			plan := testLoadPlan(t, path)
+
+			var out bytes.Buffer
+			if err := Markdown(&out, plan, MarkdownOptions{}); err != nil {
+				t.Fatal(err)
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".md.golden"))
+			g.Assert(t, name, out.Bytes())
+		})
+	}
+}
+
+func TestMarkdownTruncation(t *testing.T) {
+	plan := testLoadPlan(t, filepath.Join(testDataDir, "changes.json"))
+
+	var full bytes.Buffer
+	if err := Markdown(&full, plan, MarkdownOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		opts MarkdownOptions
+		fits bool
+	}{
+		"short":     {MarkdownOptions{MaxLength: full.Len() - 1}, true},
+		"truncated": {MarkdownOptions{MaxLength: 600}, true},
+		"head":      {MarkdownOptions{MaxLength: 1}, false},
+		"lines":     {MarkdownOptions{MaxDiffLines: 4}, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := Markdown(&out, plan, tc.opts); err != nil {
+				t.Fatal(err)
+			}
+			if tc.opts.MaxLength > 0 && (out.Len() <= tc.opts.MaxLength) != tc.fits {
+				t.Fatalf("document is %d bytes long, with a maximum of %d", out.Len(), tc.opts.MaxLength)
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".md.golden"))
+			g.Assert(t, "changes_"+name, out.Bytes())
+		})
+	}
+}
+
+func TestMarkdownTruncation_modules(t *testing.T) {
+	long := markdownItem{short: "- " + strings.Repeat("x", 200) + "\n"}
+	item := func(name string) markdownItem {
+		return markdownItem{short: "- " + name + "\n"}
+	}
+	section := func(title string) string {
+		return "\n<details><summary>" + title + "</summary>\n\n"
+	}
+	const closing = "\n</details>\n"
+
+	cases := map[string]struct {
+		sections []markdownSection
+		expected string
+	}{
+		// The short item of the second module fits, but comes after an
+		// item that does not.
+		"stops at first misfit": {
+			sections: []markdownSection{
+				{title: "a", items: []markdownItem{item("a1"), long, item("a3")}},
+				{title: "b", items: []markdownItem{item("b1")}},
+			},
+			expected: section("a") + "- a1\n" + closing + truncatedNote(3),
+		},
+		"no empty section": {
+			sections: []markdownSection{
+				{title: "a", items: []markdownItem{long}},
+				{title: "b", items: []markdownItem{item("b1")}},
+			},
+			expected: truncatedNote(2),
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			budget := len(tc.expected) + 60
+			if actual := renderModules(tc.sections, false, budget); actual != tc.expected {
+				t.Fatalf("expected:\n%s\ngot:\n%s", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMarkdownSensitive(t *testing.T) {
+	for name, path := range testPlans {
+		t.Run(name, func(t *testing.T) {
+			plan := testLoadPlan(t, path)
+
+			var out bytes.Buffer
+			if err := Markdown(&out, plan, MarkdownOptions{}); err != nil {
+				t.Fatal(err)
+			}
+			for _, secret := range []string{"hunter2", "hunter3", "s3cr3t"} {
+				if strings.Contains(out.String(), secret) {
+					t.Fatalf("document contains sensitive value %q", secret)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,253 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool     `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+	ContextRegion    *sarifRegion          `json:"contextRegion,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	EndLine     int           `json:"endLine,omitempty"`
+	EndColumn   int           `json:"endColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// SARIF writes the diagnostics to w as a SARIF 2.1.0 log, the format
+// read by code scanning tools. The diagnostics of "terraform validate
+// -json" are found in ValidateOutput.Diagnostics.
+//
+// Each diagnostic becomes a result whose rule is identified by its
+// summary, lowercased with runs of other characters than letters and
+// digits replaced by "-", so that the results of different runs can be
+// matched. The range of the diagnostic becomes the physical location of
+// the result, with the code of its snippet as context, and the context
+// of the snippet, such as the block holding the expression, becomes a
+// logical location.
+func SARIF(w io.Writer, diags []tfjson.Diagnostic) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "terraform",
+			InformationURI: "https://www.terraform.io",
+			Rules:          []sarifRule{},
+		}},
+		ColumnKind: "unicodeCodePoints",
+		Results:    []sarifResult{},
+	}
+
+	rules := make(map[string]int)
+	for _, diag := range diags {
+		id := sarifRuleID(diag.Summary)
+		index, ok := rules[id]
+		if !ok {
+			index = len(run.Tool.Driver.Rules)
+			rules[id] = index
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               id,
+				ShortDescription: sarifMessage{Text: diag.Summary},
+			})
+		}
+
+		result := sarifResult{
+			RuleID:    id,
+			RuleIndex: index,
+			Level:     sarifLevel(diag.Severity),
+			Message:   sarifMessage{Text: sarifMessageText(diag)},
+		}
+		if loc, ok := sarifDiagnosticLocation(diag); ok {
+			result.Locations = []sarifLocation{loc}
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	})
+}
+
+// sarifRuleID derives the identifier of a rule from the summary of its
+// diagnostics.
+func sarifRuleID(summary string) string {
+	var b strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(summary) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			sep = b.Len() > 0
+			continue
+		}
+		if sep {
+			b.WriteByte('-')
+			sep = false
+		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return "diagnostic"
+	}
+	return b.String()
+}
+
+func sarifLevel(severity tfjson.DiagnosticSeverity) string {
+	switch severity {
+	case tfjson.DiagnosticSeverityError:
+		return "error"
+	case tfjson.DiagnosticSeverityWarning:
+		return "warning"
+	}
+	return "note"
+}
+
+// sarifMessageText joins the summary and detail of a diagnostic, with
+// the values of the expression it refers to, as Terraform prints them.
+func sarifMessageText(diag tfjson.Diagnostic) string {
+	text := diag.Summary
+	if diag.Detail != "" {
+		text += "\n\n" + diag.Detail
+	}
+	if diag.Snippet != nil && len(diag.Snippet.Values) > 0 {
+		text += "\n"
+		for _, v := range diag.Snippet.Values {
+			text += "\n" + v.Traversal + " " + v.Statement
+		}
+	}
+	return text
+}
+
+// sarifDiagnosticLocation returns the location of a diagnostic, if it
+// has one.
+func sarifDiagnosticLocation(diag tfjson.Diagnostic) (sarifLocation, bool) {
+	var loc sarifLocation
+	if diag.Range != nil && diag.Range.Filename != "" {
+		loc.PhysicalLocation = &sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifact(diag.Range.Filename),
+		}
+		if diag.Range.Start.Line > 0 {
+			loc.PhysicalLocation.Region = &sarifRegion{
+				StartLine:   diag.Range.Start.Line,
+				StartColumn: diag.Range.Start.Column,
+				EndLine:     diag.Range.End.Line,
+				EndColumn:   diag.Range.End.Column,
+			}
+		}
+		if snippet := diag.Snippet; snippet != nil && snippet.Code != "" {
+			if loc.PhysicalLocation.Region != nil {
+				if highlight, ok := snippetHighlight(snippet); ok {
+					loc.PhysicalLocation.Region.Snippet = &sarifMessage{Text: highlight}
+				}
+			}
+			if snippet.StartLine > 0 {
+				loc.PhysicalLocation.ContextRegion = &sarifRegion{
+					StartLine: snippet.StartLine,
+					EndLine:   snippet.StartLine + strings.Count(strings.TrimSuffix(snippet.Code, "\n"), "\n"),
+					Snippet:   &sarifMessage{Text: snippet.Code},
+				}
+			}
+		}
+	}
+	if diag.Snippet != nil && diag.Snippet.Context != nil && *diag.Snippet.Context != "" {
+		loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: *diag.Snippet.Context}}
+	}
+	return loc, loc.PhysicalLocation != nil || loc.LogicalLocations != nil
+}
+
+// sarifArtifact locates a configuration file. Relative paths are
+// resolved against the root of the sources, and absolute paths become
+// file URIs.
+func sarifArtifact(filename string) sarifArtifactLocation {
+	p := filepath.ToSlash(filename)
+	if filepath.IsAbs(filename) || path.IsAbs(p) {
+		if !strings.HasPrefix(p, "/") {
+			p = "/" + p
+		}
+		return sarifArtifactLocation{URI: (&url.URL{Scheme: "file", Path: p}).String()}
+	}
+	return sarifArtifactLocation{URI: (&url.URL{Path: p}).String(), URIBaseID: "%SRCROOT%"}
+}
+
+// snippetHighlight returns the part of the code of a snippet that the
+// diagnostic refers to.
+func snippetHighlight(snippet *tfjson.DiagnosticSnippet) (string, bool) {
+	start, end := snippet.HighlightStartOffset, snippet.HighlightEndOffset
+	if start < 0 || end <= start || end > len(snippet.Code) {
+		return "", false
+	}
+	return snippet.Code[start:end], true
+}
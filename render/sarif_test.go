@@ -0,0 +1,59 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+func TestSARIFGolden(t *testing.T) {
+	b, err := os.ReadFile(filepath.Join(testDataDir, "validate.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var vo tfjson.ValidateOutput
+	if err := json.Unmarshal(b, &vo); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string][]tfjson.Diagnostic{
+		"validate": vo.Diagnostics,
+		"empty":    nil,
+	}
+	for name, diags := range cases {
+		t.Run(name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := SARIF(&out, diags); err != nil {
+				t.Fatal(err)
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".sarif.golden"))
+			g.Assert(t, name, out.Bytes())
+		})
+	}
+}
+
+func TestSARIFRuleID(t *testing.T) {
+	cases := map[string]string{
+		"Missing required argument":              "missing-required-argument",
+		"Invalid value for \"number\" parameter": "invalid-value-for-number-parameter",
+		"  Unsupported block type!":              "unsupported-block-type",
+		"Référence non déclarée":                 "référence-non-déclarée",
+		"???":                                    "diagnostic",
+		"":                                       "diagnostic",
+	}
+	for summary, expected := range cases {
+		if actual := sarifRuleID(summary); actual != expected {
+			t.Errorf("%q: expected %q, got %q", summary, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+// Package render turns plans, check results and diagnostics into
+// documents, such as the text printed by "terraform plan", JUnit reports
+// or SARIF logs, without needing Terraform.
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+// Text writes the plan to w as the "terraform plan" command displays
+// it, without colors: the changes made outside of Terraform, the
+// resource changes with their attribute diffs, the summary line and
+// the output changes.
+//
+// Values are rendered from the JSON plan, which does not tell nested
+// blocks apart from attributes: all nested values are rendered as
+// attributes.
+func Text(w io.Writer, plan *tfjson.Plan) error {
+	if plan == nil {
+		return errors.New("plan is nil")
+	}
+
+	var b strings.Builder
+	writeDrift(&b, plan)
+
+	changes := displayedChanges(plan)
+	outputs := outputChangeLines(plan)
+	switch {
+	case len(changes) > 0:
+		writeLegend(&b, changes)
+		b.WriteString("Terraform will perform the following actions:\n\n")
+		for _, rc := range changes {
+			b.WriteString(resourceChangeText(rc, false))
+			b.WriteString("\n")
+		}
+		b.WriteString(plan.Summary().String())
+		b.WriteString("\n")
+		if outputs != "" {
+			b.WriteString("\nChanges to Outputs:\n")
+			b.WriteString(outputs)
+		}
+	case outputs != "":
+		b.WriteString("Changes to Outputs:\n")
+		b.WriteString(outputs)
+		b.WriteString("\nYou can apply this plan to save these new output values to the Terraform\n")
+		b.WriteString("state, without changing any real infrastructure.\n")
+	default:
+		b.WriteString("No changes. Your infrastructure matches the configuration.\n\n")
+		b.WriteString("Terraform has compared your real infrastructure against your configuration\n")
+		b.WriteString("and found no differences, so no changes are needed.\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeDrift writes the section describing the changes made outside of
+// Terraform. When the plan lists its RelevantAttributes, only the
+// resources they refer to are shown, as Terraform does.
+func writeDrift(b *strings.Builder, plan *tfjson.Plan) {
+	relevant := make(map[string]bool)
+	for _, ra := range plan.RelevantAttributes {
+		relevant[ra.Resource] = true
+	}
+
+	var drift []*tfjson.ResourceChange
+	for _, rc := range plan.ResourceDrift {
+		if rc == nil || rc.Change == nil {
+			continue
+		}
+		if len(relevant) > 0 && !relevant[rc.Address] {
+			continue
+		}
+		drift = append(drift, rc)
+	}
+	if len(drift) == 0 {
+		return
+	}
+
+	b.WriteString("Note: Objects have changed outside of Terraform\n\n")
+	b.WriteString("Terraform detected the following changes made outside of Terraform since the\n")
+	b.WriteString("last \"terraform apply\" which may have affected this plan:\n\n")
+	for _, rc := range drift {
+		b.WriteString(resourceChangeText(rc, true))
+		b.WriteString("\n")
+	}
+	b.WriteString("Unless you have made equivalent changes to your configuration, or ignored the\n")
+	b.WriteString("relevant attributes using ignore_changes, the following plan may include\n")
+	b.WriteString("actions to undo or respond to these changes.\n\n")
+	b.WriteString(strings.Repeat("─", 77))
+	b.WriteString("\n\n")
+}
+
+// displayedChanges returns the resource changes Terraform displays:
+// those that do something, or that move or import the object.
+func displayedChanges(plan *tfjson.Plan) []*tfjson.ResourceChange {
+	var ret []*tfjson.ResourceChange
+	for _, rc := range plan.ResourceChanges {
+		if rc == nil || rc.Change == nil {
+			continue
+		}
+		actions := rc.Change.Actions
+		if actions.Delete() && rc.Mode == tfjson.DataResourceMode {
+			continue
+		}
+		if actions.NoOp() && !moved(rc) && rc.Change.Importing == nil {
+			continue
+		}
+		ret = append(ret, rc)
+	}
+	return ret
+}
+
+func moved(rc *tfjson.ResourceChange) bool {
+	return rc.PreviousAddress != "" && rc.PreviousAddress != rc.Address
+}
+
+// legendEntries lists the symbols explained at the top of the plan, in
+// the order Terraform shows them.
+var legendEntries = []struct {
+	symbol string
+	text   string
+	match  func(tfjson.Actions) bool
+}{
+	{"+", "create", tfjson.Actions.Create},
+	{"~", "update in-place", tfjson.Actions.Update},
+	{"-", "destroy", tfjson.Actions.Delete},
+	{"-/+", "destroy and then create replacement", tfjson.Actions.DestroyBeforeCreate},
+	{"+/-", "create replacement and then destroy", tfjson.Actions.CreateBeforeDestroy},
+	{"<=", "read (data resources)", tfjson.Actions.Read},
+	{".", "forget", tfjson.Actions.Forget},
+}
+
+// writeLegend explains the symbols used by changes, if any.
+func writeLegend(b *strings.Builder, changes []*tfjson.ResourceChange) {
+	var legend strings.Builder
+	for _, entry := range legendEntries {
+		for _, rc := range changes {
+			if entry.match(rc.Change.Actions) {
+				fmt.Fprintf(&legend, "%3s %s\n", entry.symbol, entry.text)
+				break
+			}
+		}
+	}
+	if legend.Len() == 0 {
+		return
+	}
+	b.WriteString("Terraform used the selected providers to generate the following execution\n")
+	b.WriteString("plan. Resource actions are indicated with the following symbols:\n")
+	b.WriteString(legend.String())
+	b.WriteString("\n")
+}
+
+// actionSymbol returns the marker shown in front of a resource.
+func actionSymbol(actions tfjson.Actions) string {
+	switch {
+	case actions.Create():
+		return "+"
+	case actions.Update():
+		return "~"
+	case actions.Delete():
+		return "-"
+	case actions.DestroyBeforeCreate():
+		return "-/+"
+	case actions.CreateBeforeDestroy():
+		return "+/-"
+	case actions.Read():
+		return "<="
+	case actions.Forget():
+		return "."
+	}
+	return ""
+}
+
+// resourceChangeText renders a resource change, or a change made
+// outside of Terraform when drift is true, as a comment header followed
+// by the resource block with its attribute diff.
+func resourceChangeText(rc *tfjson.ResourceChange, drift bool) string {
+	w := &diffWriter{}
+	if paths, err := rc.Change.ReplaceAttributePaths(); err == nil {
+		w.replacePaths = paths
+	}
+
+	for _, comment := range resourceComments(rc, drift) {
+		fmt.Fprintf(&w.b, "  # %s\n", comment)
+	}
+
+	keyword := "resource"
+	if rc.Mode == tfjson.DataResourceMode {
+		keyword = "data"
+	}
+	w.line(0, actionSymbol(rc.Change.Actions), "%s %q %q {", keyword, rc.Type, rc.Name)
+
+	before, after := rc.Change.BeforeAnnotated(), rc.Change.AfterAnnotated()
+	if rc.Change.Actions.Forget() {
+		after = before
+	}
+	w.attributes(1, nil, valueAction(before, after), before, after, true)
+	w.line(0, "", "}")
+	return w.String()
+}
+
+// resourceComments returns the comments shown above a resource block,
+// starting with the one describing its action.
+func resourceComments(rc *tfjson.ResourceChange, drift bool) []string {
+	addr := rc.Address
+	if rc.DeposedKey != "" {
+		addr = fmt.Sprintf("%s (deposed object %s)", addr, rc.DeposedKey)
+	}
+	actions := rc.Change.Actions
+
+	if drift {
+		if actions.Delete() {
+			return []string{addr + " has been deleted"}
+		}
+		return []string{addr + " has changed"}
+	}
+
+	var header string
+	switch {
+	case actions.Create():
+		header = addr + " will be created"
+	case actions.Read():
+		header = addr + " will be read during apply"
+	case actions.Update():
+		header = addr + " will be updated in-place"
+	case actions.Replace():
+		switch rc.ActionReason {
+		case tfjson.ActionReasonReplaceBecauseTainted:
+			header = addr + " is tainted, so must be replaced"
+		case tfjson.ActionReasonReplaceByRequest:
+			header = addr + " will be replaced, as requested"
+		case tfjson.ActionReasonReplaceByTriggers:
+			header = addr + " will be replaced due to changes in replace_triggered_by"
+		default:
+			header = addr + " must be replaced"
+		}
+	case actions.Delete():
+		header = addr + " will be destroyed"
+	case actions.Forget():
+		header = addr + " will no longer be managed by Terraform"
+	case moved(rc):
+		return []string{fmt.Sprintf("%s has moved to %s", rc.PreviousAddress, addr)}
+	default:
+		header = addr + " will be imported"
+		if rc.Change.GeneratedConfig != "" {
+			return []string{header, "(config will be generated)"}
+		}
+		return []string{header}
+	}
+
+	comments := []string{header}
+	if reason := actionReasonComment(rc); reason != "" {
+		comments = append(comments, reason)
+	}
+	if moved(rc) {
+		comments = append(comments, fmt.Sprintf("(moved from %s)", rc.PreviousAddress))
+	}
+	if rc.Change.Importing != nil {
+		comments = append(comments, fmt.Sprintf("(imported from %q)", rc.Change.Importing.ID))
+	}
+	if rc.Change.GeneratedConfig != "" {
+		comments = append(comments, "(config will be generated)")
+	}
+	return comments
+}
+
+// actionReasonComment explains why a resource is destroyed or read
+// during apply, as Terraform does.
+func actionReasonComment(rc *tfjson.ResourceChange) string {
+	switch rc.ActionReason {
+	case tfjson.ActionReasonDeleteBecauseNoResourceConfig:
+		return fmt.Sprintf("(because %s is not in configuration)", rc.Address)
+	case tfjson.ActionReasonDeleteBecauseNoModule:
+		return fmt.Sprintf("(because %s is not in configuration)", rc.ModuleAddress)
+	case tfjson.ActionReasonDeleteBecauseCountIndex:
+		return fmt.Sprintf("(because index [%s] is out of range for count)", literal(rc.Index))
+	case tfjson.ActionReasonDeleteBecauseEachKey:
+		return fmt.Sprintf("(because key [%s] is not in for_each map)", literal(rc.Index))
+	case tfjson.ActionReasonDeleteBecauseWrongRepetition:
+		switch rc.Index.(type) {
+		case nil:
+			return "(because resource uses count or for_each)"
+		case string:
+			return "(because resource does not use for_each)"
+		}
+		return "(because resource does not use count)"
+	case tfjson.ActionReasonDeleteBecauseNoMoveTarget:
+		return "(because it was moved to an address that is not in configuration)"
+	case tfjson.ActionReasonReadBecauseConfigUnknown:
+		return "(config refers to values not yet known)"
+	case tfjson.ActionReasonReadBecauseDependencyPending:
+		return "(depends on a resource or a module with changes pending)"
+	case tfjson.ActionReasonReadBecauseCheckNested:
+		return "(config will be reloaded to verify a check block)"
+	}
+	return ""
+}
+
+// outputChangeLines renders the changed outputs, sorted by name.
+func outputChangeLines(plan *tfjson.Plan) string {
+	var names []string
+	width := 0
+	for name, oc := range plan.OutputChanges {
+		if oc == nil || oc.Actions.NoOp() {
+			continue
+		}
+		names = append(names, name)
+		if len(displayKey(name)) > width {
+			width = len(displayKey(name))
+		}
+	}
+	sort.Strings(names)
+
+	w := &diffWriter{}
+	for _, name := range names {
+		oc := plan.OutputChanges[name]
+		key := displayKey(name)
+		w.entry(0, key+strings.Repeat(" ", width-len(key)), nil, oc.BeforeAnnotated(), oc.AfterAnnotated())
+	}
+	return w.String()
+}
@@ -0,0 +1,66 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sebdah/goldie/v2"
+
+	"github.com/terramate-io/tfjson/v2"
+)
+
+const testDataDir = "testdata"
+
+// testPlans lists the plans rendered by the golden tests: the synthetic
+// plans of this package, then fixtures of the parent package.
+var testPlans = map[string]string{
+	"changes":     filepath.Join(testDataDir, "changes.json"),
+	"checks":      filepath.Join(testDataDir, "checks.json"),
+	"basic":       filepath.Join("..", "testdata", "basic", "plan.json"),
+	"has_changes": filepath.Join("..", "testdata", "has_changes", "plan.json"),
+	"moved_block": filepath.Join("..", "testdata", "moved_block", "plan.json"),
+	"no_changes":  filepath.Join("..", "testdata", "no_changes", "plan.json"),
+	"sensitive":   filepath.Join("..", "testdata", "110_sensitive_values", "plan.json"),
+}
+
+func testLoadPlan(t *testing.T, path string) *tfjson.Plan {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := new(tfjson.Plan)
+	if err := json.Unmarshal(b, plan); err != nil {
+		t.Fatal(err)
+	}
+	return plan
+}
+
+func TestTextNil(t *testing.T) {
+	if err := Text(new(bytes.Buffer), nil); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestTextGolden(t *testing.T) {
+	for name, path := range testPlans {
+		t.Run(name, func(t *testing.T) {
+			plan := testLoadPlan(t, path)
+
+			var out bytes.Buffer
+			if err := Text(&out, plan); err != nil {
+				t.Fatal(err)
+			}
+
+			g := goldie.New(t, goldie.WithFixtureDir(testDataDir), goldie.WithNameSuffix(".txt.golden"))
+			g.Assert(t, name, out.Bytes())
+		})
+	}
+}
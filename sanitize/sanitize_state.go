@@ -34,27 +34,15 @@ func SanitizeStateModule(
 	mode SanitizeStateModuleChangeMode,
 	replaceWith interface{},
 ) {
-	if result == nil {
-		return
-	}
-
-	for _, v := range result.Resources {
+	_ = result.Walk(func(_ *tfjson.StateModule, v *tfjson.StateResource) error {
 		sanitizeStateResource(
 			v,
 			findResourceChange(resourceChanges, v.Address),
 			mode,
 			replaceWith,
 		)
-	}
-
-	for _, v := range result.ChildModules {
-		SanitizeStateModule(
-			v,
-			resourceChanges,
-			mode,
-			replaceWith,
-		)
-	}
+		return nil
+	})
 }
 
 func sanitizeStateResource(
@@ -0,0 +1,187 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ProviderSchemaNotFoundError is returned when ProviderSchemas has no
+// schema for a provider.
+type ProviderSchemaNotFoundError struct {
+	// The provider that was looked up.
+	Provider ProviderAddress
+}
+
+func (e *ProviderSchemaNotFoundError) Error() string {
+	return fmt.Sprintf("no schema found for provider %s", e.Provider)
+}
+
+// ResourceSchemaNotFoundError is returned when a provider schema has no
+// schema for a resource type or data source.
+type ResourceSchemaNotFoundError struct {
+	// The provider whose schema was searched.
+	Provider ProviderAddress
+
+	// The mode and type of the resource that was looked up.
+	Mode ResourceMode
+	Type string
+}
+
+func (e *ResourceSchemaNotFoundError) Error() string {
+	kind := "resource type"
+	if e.Mode == DataResourceMode {
+		kind = "data source"
+	}
+	return fmt.Sprintf("provider %s has no schema for %s %q", e.Provider, kind, e.Type)
+}
+
+// SchemaVersionMismatchError is returned when the schema version
+// recorded in a StateResource differs from the version of the schema
+// found for it, meaning the values were written by a different version
+// of the provider.
+type SchemaVersionMismatchError struct {
+	// The address of the resource.
+	Address string
+
+	// The schema version recorded in the state.
+	StateVersion uint64
+
+	// The version of the schema found in ProviderSchemas.
+	SchemaVersion uint64
+}
+
+func (e *SchemaVersionMismatchError) Error() string {
+	return fmt.Sprintf("resource %s has schema version %d in state, but the provider schema is version %d",
+		e.Address, e.StateVersion, e.SchemaVersion)
+}
+
+// ProviderSchema returns the schema of a provider, given any of the
+// address forms accepted by ParseProviderAddress. Addresses are
+// compared using ProviderAddress.Equivalent, so that for instance
+// "aws", "hashicorp/aws" and "registry.opentofu.org/hashicorp/aws" all
+// find the schema keyed by "registry.terraform.io/hashicorp/aws".
+//
+// Terraform 0.12 records aliased providers in ResourceChange.ProviderName
+// as "aws.alias": the alias is ignored.
+//
+// A *ProviderSchemaNotFoundError is returned if there is no schema for
+// the provider.
+func (p *ProviderSchemas) ProviderSchema(provider string) (*ProviderSchema, error) {
+	provider = trimLegacyProviderAlias(provider)
+	addr, err := ParseProviderAddress(provider)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, &ProviderSchemaNotFoundError{Provider: addr}
+	}
+
+	if ps, ok := p.Schemas[provider]; ok {
+		return ps, nil
+	}
+
+	// Prefer an identical address over an equivalent one, in case the
+	// schemas contain both.
+	keys := make([]string, 0, len(p.Schemas))
+	for key := range p.Schemas {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var found *ProviderSchema
+	for _, key := range keys {
+		keyAddr, err := ParseProviderAddress(key)
+		if err != nil {
+			continue
+		}
+		if keyAddr.Equal(addr) {
+			return p.Schemas[key], nil
+		}
+		if found == nil && keyAddr.Equivalent(addr) {
+			found = p.Schemas[key]
+		}
+	}
+	if found == nil {
+		return nil, &ProviderSchemaNotFoundError{Provider: addr}
+	}
+	return found, nil
+}
+
+// ResourceSchema returns the schema of a resource type or data source
+// of a provider. An empty mode is treated as ManagedResourceMode.
+//
+// A *ProviderSchemaNotFoundError is returned if there is no schema for
+// the provider, and a *ResourceSchemaNotFoundError if the provider has
+// no schema for the resource type.
+func (p *ProviderSchemas) ResourceSchema(provider string, mode ResourceMode, resourceType string) (*Schema, error) {
+	ps, err := p.ProviderSchema(provider)
+	if err != nil {
+		return nil, err
+	}
+
+	mode = resourceModeOrManaged(mode)
+	schemas := ps.ResourceSchemas
+	if mode == DataResourceMode {
+		schemas = ps.DataSourceSchemas
+	}
+
+	schema, ok := schemas[resourceType]
+	if !ok || schema == nil {
+		addr, _ := ParseProviderAddress(trimLegacyProviderAlias(provider))
+		return nil, &ResourceSchemaNotFoundError{Provider: addr, Mode: mode, Type: resourceType}
+	}
+	return schema, nil
+}
+
+// ResourceChangeSchema returns the schema of the resource of a
+// ResourceChange. See ResourceSchema for the errors returned.
+func (p *ProviderSchemas) ResourceChangeSchema(rc *ResourceChange) (*Schema, error) {
+	if rc == nil {
+		return nil, errors.New("resource change is nil")
+	}
+	return p.ResourceSchema(rc.ProviderName, rc.Mode, rc.Type)
+}
+
+// StateResourceSchema returns the schema of a StateResource. See
+// ResourceSchema for the errors returned.
+//
+// If the schema is found but its version differs from
+// StateResource.SchemaVersion, the schema is returned along with a
+// *SchemaVersionMismatchError, so that callers may decide whether to
+// use it anyway.
+func (p *ProviderSchemas) StateResourceSchema(r *StateResource) (*Schema, error) {
+	if r == nil {
+		return nil, errors.New("state resource is nil")
+	}
+
+	schema, err := p.ResourceSchema(r.ProviderName, r.Mode, r.Type)
+	if err != nil {
+		return nil, err
+	}
+	if schema.Version != r.SchemaVersion {
+		return schema, &SchemaVersionMismatchError{
+			Address:       r.Address,
+			StateVersion:  r.SchemaVersion,
+			SchemaVersion: schema.Version,
+		}
+	}
+	return schema, nil
+}
+
+// trimLegacyProviderAlias removes the alias from a legacy provider
+// name such as "aws.alias". Provider types cannot contain dots, so
+// this never alters a valid source address.
+func trimLegacyProviderAlias(provider string) string {
+	if strings.Contains(provider, "/") {
+		return provider
+	}
+	if i := strings.IndexByte(provider, '.'); i >= 0 {
+		return provider[:i]
+	}
+	return provider
+}
@@ -0,0 +1,128 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestProviderSchemasResourceSchema(t *testing.T) {
+	cases := []struct {
+		fixture  string
+		provider string
+		mode     ResourceMode
+		typ      string
+	}{
+		// Legacy schemas keyed by provider type only.
+		{"basic", "registry.terraform.io/hashicorp/null", ManagedResourceMode, "null_resource"},
+		{"basic", "-/null", DataResourceMode, "null_data_source"},
+		{"basic", "aws", "", "aws_instance"},
+		{"basic", "null.aliased", ManagedResourceMode, "null_resource"},
+		// Schemas keyed by fully-qualified addresses.
+		{"110_basic", "null", ManagedResourceMode, "null_resource"},
+		{"110_basic", "hashicorp/null", DataResourceMode, "null_data_source"},
+		{"110_basic", "registry.opentofu.org/hashicorp/aws", ManagedResourceMode, "aws_instance"},
+		{"110_basic", "Registry.Terraform.io/HashiCorp/AWS", ManagedResourceMode, "aws_instance"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.fixture+"/"+tc.provider+"/"+tc.typ, func(t *testing.T) {
+			schemas := testLoadSchemas(t, tc.fixture)
+
+			ps, err := schemas.ProviderSchema(tc.provider)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected := ps.ResourceSchemas[tc.typ]
+			if tc.mode == DataResourceMode {
+				expected = ps.DataSourceSchemas[tc.typ]
+			}
+
+			actual, err := schemas.ResourceSchema(tc.provider, tc.mode, tc.typ)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual == nil || actual != expected {
+				t.Fatalf("unexpected schema %#v", actual)
+			}
+		})
+	}
+}
+
+func TestProviderSchemasResourceSchema_errors(t *testing.T) {
+	schemas := testLoadSchemas(t, "110_basic")
+
+	_, err := schemas.ResourceSchema("hashicorp/google", ManagedResourceMode, "google_compute_instance")
+	var providerErr *ProviderSchemaNotFoundError
+	if !errors.As(err, &providerErr) {
+		t.Fatalf("expected ProviderSchemaNotFoundError, got %v", err)
+	}
+	if providerErr.Provider.String() != "registry.terraform.io/hashicorp/google" {
+		t.Fatalf("unexpected provider %s", providerErr.Provider)
+	}
+
+	_, err = schemas.ResourceSchema("hashicorp/null", DataResourceMode, "null_resource")
+	var resourceErr *ResourceSchemaNotFoundError
+	if !errors.As(err, &resourceErr) {
+		t.Fatalf("expected ResourceSchemaNotFoundError, got %v", err)
+	}
+	if expected := `provider registry.terraform.io/hashicorp/null has no schema for data source "null_resource"`; err.Error() != expected {
+		t.Fatalf("expected error %q, got %q", expected, err)
+	}
+
+	if _, err := schemas.ResourceSchema("not a provider", ManagedResourceMode, "x"); err == nil {
+		t.Fatal("expected error for invalid provider address")
+	}
+}
+
+func TestProviderSchemasResourceChangeSchema(t *testing.T) {
+	schemas := testLoadSchemas(t, "110_basic")
+	plan := testLoadPlan(t, "110_basic")
+
+	for _, rc := range plan.ResourceChanges {
+		schema, err := schemas.ResourceChangeSchema(rc)
+		if err != nil {
+			t.Fatalf("%s: %s", rc.Address, err)
+		}
+		if _, err := rc.Change.AfterValue(schema); err != nil {
+			t.Fatalf("%s: %s", rc.Address, err)
+		}
+	}
+}
+
+func TestProviderSchemasStateResourceSchema(t *testing.T) {
+	schemas := testLoadSchemas(t, "110_basic")
+	expected := schemas.Schemas["registry.terraform.io/hashicorp/null"].ResourceSchemas["null_resource"]
+
+	r := &StateResource{
+		Address:       "null_resource.foo",
+		Mode:          ManagedResourceMode,
+		Type:          "null_resource",
+		ProviderName:  "registry.terraform.io/hashicorp/null",
+		SchemaVersion: expected.Version,
+	}
+
+	actual, err := schemas.StateResourceSchema(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual != expected {
+		t.Fatalf("unexpected schema %#v", actual)
+	}
+
+	r.SchemaVersion = expected.Version + 1
+	actual, err = schemas.StateResourceSchema(r)
+	var versionErr *SchemaVersionMismatchError
+	if !errors.As(err, &versionErr) {
+		t.Fatalf("expected SchemaVersionMismatchError, got %v", err)
+	}
+	if actual != expected {
+		t.Fatal("expected schema to be returned along with the version mismatch")
+	}
+	if versionErr.StateVersion != r.SchemaVersion || versionErr.SchemaVersion != expected.Version {
+		t.Fatalf("unexpected versions in %#v", versionErr)
+	}
+}
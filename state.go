@@ -4,7 +4,6 @@
 package tfjson
 
 import (
-	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -19,11 +18,9 @@ var StateFormatVersionConstraints = ">= 0.1, < 2.0"
 
 // State is the top-level representation of a Terraform state.
 type State struct {
-	// useJSONNumber opts into the behavior of calling
-	// json.Decoder.UseNumber prior to decoding the state, which turns
-	// numbers into json.Numbers instead of float64s. Set it using
-	// State.UseJSONNumber.
-	useJSONNumber bool
+	// decodeOptions controls how the state is decoded. Set it using
+	// State.SetDecodeOptions or State.UseJSONNumber.
+	decodeOptions DecodeOptions
 
 	// The version of the state format. This should always match the
 	// StateFormatVersion constant in this package, or else am
@@ -45,8 +42,18 @@ type State struct {
 // json.Number behavior or the float64 behavior. When b is true, the State will
 // represent numbers in StateOutputs as json.Numbers. When b is false, the
 // State will represent numbers in StateOutputs as float64s.
+//
+// It is a shorthand for setting DecodeOptions.Numbers.
 func (s *State) UseJSONNumber(b bool) {
-	s.useJSONNumber = b
+	s.decodeOptions.Numbers = NumberFloat64
+	if b {
+		s.decodeOptions.Numbers = NumberJSONNumber
+	}
+}
+
+// SetDecodeOptions sets the options used when the State is decoded.
+func (s *State) SetDecodeOptions(opts DecodeOptions) {
+	s.decodeOptions = opts
 }
 
 // Validate checks to ensure that the state is present, and the
@@ -82,16 +89,16 @@ func (s *State) UnmarshalJSON(b []byte) error {
 	type rawState State
 	var state rawState
 
-	dec := json.NewDecoder(bytes.NewReader(b))
-	if s.useJSONNumber {
-		dec.UseNumber()
-	}
-	err := dec.Decode(&state)
-	if err != nil {
+	opts := s.decodeOptions
+	if err := opts.decode(b, &state); err != nil {
 		return err
 	}
+	if opts.Numbers == NumberBigFloat {
+		toBigFloats(&state)
+	}
 
 	*s = *(*State)(&state)
+	s.decodeOptions = opts
 
 	return s.Validate()
 }
@@ -175,6 +182,15 @@ type StateResource struct {
 	DeposedKey string `json:"deposed_key,omitempty"`
 }
 
+// MarshalJSON implements json.Marshaler for StateResource.
+func (r *StateResource) MarshalJSON() ([]byte, error) {
+	type rawStateResource StateResource
+	raw := rawStateResource(*r)
+	raw.Index = jsonNumbers(raw.Index)
+	raw.AttributeValues, _ = jsonNumbers(raw.AttributeValues).(map[string]interface{})
+	return json.Marshal(&raw)
+}
+
 // StateOutput represents an output value in a common state
 // representation.
 type StateOutput struct {
@@ -203,7 +219,7 @@ type jsonStateOutput struct {
 func (so *StateOutput) MarshalJSON() ([]byte, error) {
 	jsonSa := &jsonStateOutput{
 		Sensitive: so.Sensitive,
-		Value:     so.Value,
+		Value:     jsonNumbers(so.Value),
 	}
 	if so.Type != cty.NilType {
 		outputType, _ := so.Type.MarshalJSON()
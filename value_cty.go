@@ -0,0 +1,469 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// ValueMark is the type of the cty marks applied to the values
+// converted by this package.
+type ValueMark string
+
+// SensitiveMark marks the converted values that are sensitive.
+const SensitiveMark ValueMark = "sensitive"
+
+// Value converts the values of a resource instance, such as
+// StateResource.AttributeValues or Change.After, to a cty.Value of
+// the object type implied by the schema.
+//
+// The unknown and sensitive masks follow the conventions of
+// Change.AfterUnknown and Change.AfterSensitive respectively, and
+// either may be nil. Values they mark are returned as unknown values,
+// and with SensitiveMark, respectively.
+//
+// Numbers decoded as json.Number or *big.Float (see DecodeOptions)
+// are converted exactly. Numbers decoded as float64 are converted from
+// their shortest decimal representation.
+func (s *Schema) Value(values, unknown, sensitive interface{}) (cty.Value, error) {
+	if s == nil || s.Block == nil {
+		return cty.NilVal, errors.New("schema has no block")
+	}
+	return blockValue(nil, s.Block, values, unknown, sensitive)
+}
+
+// BeforeValue converts Before to a cty.Value using the schema of the
+// resource, marking the values listed in BeforeSensitive.
+func (c *Change) BeforeValue(schema *Schema) (cty.Value, error) {
+	return schema.Value(c.Before, nil, c.BeforeSensitive)
+}
+
+// AfterValue converts After to a cty.Value using the schema of the
+// resource, marking the values listed in AfterUnknown and
+// AfterSensitive.
+func (c *Change) AfterValue(schema *Schema) (cty.Value, error) {
+	return schema.Value(c.After, c.AfterUnknown, c.AfterSensitive)
+}
+
+// Value converts AttributeValues to a cty.Value using the schema of
+// the resource, marking the values listed in SensitiveValues.
+func (r *StateResource) Value(schema *Schema) (cty.Value, error) {
+	var values interface{}
+	if r.AttributeValues != nil {
+		values = r.AttributeValues
+	}
+	return schema.Value(values, nil, r.SensitiveValues)
+}
+
+// convertMasked handles the unknown and null cases common to all
+// values, calls convert otherwise, and applies SensitiveMark if
+// needed.
+func convertMasked(ty cty.Type, v, unknown, sensitive interface{}, convert func() (cty.Value, error)) (cty.Value, error) {
+	var val cty.Value
+	switch {
+	case maskAtPath(unknown, nil):
+		val = cty.UnknownVal(ty)
+	case v == nil:
+		val = cty.NullVal(ty)
+	default:
+		var err error
+		if val, err = convert(); err != nil {
+			return cty.NilVal, err
+		}
+	}
+
+	if maskAtPath(sensitive, nil) {
+		val = val.Mark(SensitiveMark)
+	}
+	return val, nil
+}
+
+// maskChild returns the part of the mask tree that applies to step.
+func maskChild(mask interface{}, step AttributePathStep) interface{} {
+	child, _ := valueAtPath(mask, AttributePath{step})
+	return child
+}
+
+func blockValue(path AttributePath, b *SchemaBlock, v, unknown, sensitive interface{}) (cty.Value, error) {
+	return convertMasked(b.ImpliedType(), v, unknown, sensitive, func() (cty.Value, error) {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return cty.NilVal, valueTypeError(path, "an object", v)
+		}
+		if err := checkObjectKeys(path, obj, func(name string) bool {
+			_, isAttr := b.Attributes[name]
+			_, isBlock := b.NestedBlocks[name]
+			return isAttr || isBlock
+		}); err != nil {
+			return cty.NilVal, err
+		}
+
+		vals := make(map[string]cty.Value, len(b.Attributes)+len(b.NestedBlocks))
+		for name, attr := range b.Attributes {
+			step := AttributeStep(name)
+			val, err := attributeValue(path.child(step), attr, obj[name], maskChild(unknown, step), maskChild(sensitive, step))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[name] = val
+		}
+		for name, bt := range b.NestedBlocks {
+			step := AttributeStep(name)
+			ety := bt.Block.ImpliedType()
+			convertElem := func(path AttributePath, v, unknown, sensitive interface{}) (cty.Value, error) {
+				return blockValue(path, bt.Block, v, unknown, sensitive)
+			}
+			ty := nestedImpliedType(bt.NestingMode, ety)
+			val, err := nestedValue(path.child(step), bt.NestingMode, ty, ety, convertElem, obj[name], maskChild(unknown, step), maskChild(sensitive, step))
+			if err != nil {
+				return cty.NilVal, err
+			}
+			vals[name] = val
+		}
+		return cty.ObjectVal(vals), nil
+	})
+}
+
+func attributeValue(path AttributePath, attr *SchemaAttribute, v, unknown, sensitive interface{}) (cty.Value, error) {
+	nt := attr.AttributeNestedType
+	if nt == nil {
+		return typedValue(path, attr.ImpliedType(), v, unknown, sensitive)
+	}
+
+	ety := nt.objectType()
+	convertElem := func(path AttributePath, v, unknown, sensitive interface{}) (cty.Value, error) {
+		return convertMasked(ety, v, unknown, sensitive, func() (cty.Value, error) {
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return cty.NilVal, valueTypeError(path, "an object", v)
+			}
+			if err := checkObjectKeys(path, obj, func(name string) bool {
+				_, ok := nt.Attributes[name]
+				return ok
+			}); err != nil {
+				return cty.NilVal, err
+			}
+
+			vals := make(map[string]cty.Value, len(nt.Attributes))
+			for name, attr := range nt.Attributes {
+				step := AttributeStep(name)
+				val, err := attributeValue(path.child(step), attr, obj[name], maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[name] = val
+			}
+			return cty.ObjectVal(vals), nil
+		})
+	}
+	return nestedValue(path, nt.NestingMode, nt.ImpliedType(), ety, convertElem, v, unknown, sensitive)
+}
+
+// elemConverter converts a single object of a nested block or nested
+// attribute type.
+type elemConverter func(path AttributePath, v, unknown, sensitive interface{}) (cty.Value, error)
+
+// nestedValue converts the value of type ty of a nested block or
+// nested attribute type according to its nesting mode, using
+// convertElem for each of the objects of type ety it contains.
+func nestedValue(path AttributePath, mode SchemaNestingMode, ty, ety cty.Type, convertElem elemConverter, v, unknown, sensitive interface{}) (cty.Value, error) {
+	if mode == SchemaNestingModeSingle || mode == SchemaNestingModeGroup {
+		return convertElem(path, v, unknown, sensitive)
+	}
+
+	return convertMasked(ty, v, unknown, sensitive, func() (cty.Value, error) {
+		switch mode {
+		case SchemaNestingModeList, SchemaNestingModeSet:
+			list, ok := v.([]interface{})
+			if !ok {
+				return cty.NilVal, valueTypeError(path, "a list", v)
+			}
+			vals := make([]cty.Value, len(list))
+			for i, ev := range list {
+				step := IndexStep(i)
+				val, err := convertElem(path.child(step), ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[i] = val
+			}
+			if ty == cty.DynamicPseudoType {
+				return cty.TupleVal(vals), nil
+			}
+			if err := checkElementTypes(path, vals); err != nil {
+				return cty.NilVal, err
+			}
+			if mode == SchemaNestingModeSet {
+				return setValue(ety, vals), nil
+			}
+			return listValue(ety, vals), nil
+
+		case SchemaNestingModeMap:
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return cty.NilVal, valueTypeError(path, "a map", v)
+			}
+			vals := make(map[string]cty.Value, len(obj))
+			for key, ev := range obj {
+				step := KeyStep(key)
+				val, err := convertElem(path.child(step), ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[key] = val
+			}
+			if ty == cty.DynamicPseudoType {
+				return cty.ObjectVal(vals), nil
+			}
+			if err := checkElementTypes(path, mapValues(vals)); err != nil {
+				return cty.NilVal, err
+			}
+			return mapValue(ety, vals), nil
+		}
+		return cty.NilVal, fmt.Errorf("%s: unsupported nesting mode %q", pathDisplay(path), mode)
+	})
+}
+
+// typedValue converts v to a value of type ty.
+func typedValue(path AttributePath, ty cty.Type, v, unknown, sensitive interface{}) (cty.Value, error) {
+	if ty == cty.DynamicPseudoType {
+		return dynamicValue(path, v, unknown, sensitive)
+	}
+
+	return convertMasked(ty, v, unknown, sensitive, func() (cty.Value, error) {
+		switch {
+		case ty.IsPrimitiveType():
+			return primitiveValue(path, ty, v)
+
+		case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+			list, ok := v.([]interface{})
+			if !ok {
+				return cty.NilVal, valueTypeError(path, "a list", v)
+			}
+			if ty.IsTupleType() && len(list) != ty.Length() {
+				return cty.NilVal, fmt.Errorf("%s: expected %d elements, got %d", pathDisplay(path), ty.Length(), len(list))
+			}
+			vals := make([]cty.Value, len(list))
+			for i, ev := range list {
+				var ety cty.Type
+				if ty.IsTupleType() {
+					ety = ty.TupleElementType(i)
+				} else {
+					ety = ty.ElementType()
+				}
+				step := IndexStep(i)
+				val, err := typedValue(path.child(step), ety, ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[i] = val
+			}
+			switch {
+			case ty.IsTupleType():
+				return cty.TupleVal(vals), nil
+			case ty.IsSetType():
+				if err := checkElementTypes(path, vals); err != nil {
+					return cty.NilVal, err
+				}
+				return setValue(ty.ElementType(), vals), nil
+			}
+			if err := checkElementTypes(path, vals); err != nil {
+				return cty.NilVal, err
+			}
+			return listValue(ty.ElementType(), vals), nil
+
+		case ty.IsMapType() || ty.IsObjectType():
+			obj, ok := v.(map[string]interface{})
+			if !ok {
+				return cty.NilVal, valueTypeError(path, "an object", v)
+			}
+			if ty.IsObjectType() {
+				if err := checkObjectKeys(path, obj, ty.HasAttribute); err != nil {
+					return cty.NilVal, err
+				}
+				vals := make(map[string]cty.Value, len(ty.AttributeTypes()))
+				for name, aty := range ty.AttributeTypes() {
+					step := AttributeStep(name)
+					val, err := typedValue(path.child(step), aty, obj[name], maskChild(unknown, step), maskChild(sensitive, step))
+					if err != nil {
+						return cty.NilVal, err
+					}
+					vals[name] = val
+				}
+				return cty.ObjectVal(vals), nil
+			}
+
+			vals := make(map[string]cty.Value, len(obj))
+			for key, ev := range obj {
+				step := KeyStep(key)
+				val, err := typedValue(path.child(step), ty.ElementType(), ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[key] = val
+			}
+			if err := checkElementTypes(path, mapValues(vals)); err != nil {
+				return cty.NilVal, err
+			}
+			return mapValue(ty.ElementType(), vals), nil
+		}
+		return cty.NilVal, fmt.Errorf("%s: unsupported type %s", pathDisplay(path), ty.FriendlyName())
+	})
+}
+
+// dynamicValue converts v to a value whose type is derived from v
+// itself: objects become cty objects and lists become cty tuples.
+func dynamicValue(path AttributePath, v, unknown, sensitive interface{}) (cty.Value, error) {
+	return convertMasked(cty.DynamicPseudoType, v, unknown, sensitive, func() (cty.Value, error) {
+		switch tv := v.(type) {
+		case string:
+			return cty.StringVal(tv), nil
+		case bool:
+			return cty.BoolVal(tv), nil
+		case map[string]interface{}:
+			vals := make(map[string]cty.Value, len(tv))
+			for key, ev := range tv {
+				step := AttributeStep(key)
+				val, err := dynamicValue(path.child(step), ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[key] = val
+			}
+			return cty.ObjectVal(vals), nil
+		case []interface{}:
+			vals := make([]cty.Value, len(tv))
+			for i, ev := range tv {
+				step := IndexStep(i)
+				val, err := dynamicValue(path.child(step), ev, maskChild(unknown, step), maskChild(sensitive, step))
+				if err != nil {
+					return cty.NilVal, err
+				}
+				vals[i] = val
+			}
+			return cty.TupleVal(vals), nil
+		}
+		return numberValue(path, v)
+	})
+}
+
+func primitiveValue(path AttributePath, ty cty.Type, v interface{}) (cty.Value, error) {
+	switch ty {
+	case cty.String:
+		if s, ok := v.(string); ok {
+			return cty.StringVal(s), nil
+		}
+		return cty.NilVal, valueTypeError(path, "a string", v)
+	case cty.Bool:
+		if b, ok := v.(bool); ok {
+			return cty.BoolVal(b), nil
+		}
+		return cty.NilVal, valueTypeError(path, "a bool", v)
+	}
+	return numberValue(path, v)
+}
+
+// numberValue converts a JSON number to an exact cty number.
+func numberValue(path AttributePath, v interface{}) (cty.Value, error) {
+	var s string
+	switch n := v.(type) {
+	case json.Number:
+		s = n.String()
+	case float64:
+		s = strconv.FormatFloat(n, 'g', -1, 64)
+	case int:
+		return cty.NumberIntVal(int64(n)), nil
+	case int64:
+		return cty.NumberIntVal(n), nil
+	case *big.Float:
+		if n == nil {
+			return cty.NilVal, valueTypeError(path, "a number", v)
+		}
+		return cty.NumberVal(new(big.Float).Copy(n)), nil
+	default:
+		return cty.NilVal, valueTypeError(path, "a number", v)
+	}
+
+	val, err := cty.ParseNumberVal(s)
+	if err != nil {
+		return cty.NilVal, fmt.Errorf("%s: %w", pathDisplay(path), err)
+	}
+	return val, nil
+}
+
+func listValue(ety cty.Type, vals []cty.Value) cty.Value {
+	if len(vals) == 0 {
+		return cty.ListValEmpty(ety)
+	}
+	return cty.ListVal(vals)
+}
+
+func setValue(ety cty.Type, vals []cty.Value) cty.Value {
+	if len(vals) == 0 {
+		return cty.SetValEmpty(ety)
+	}
+	return cty.SetVal(vals)
+}
+
+func mapValue(ety cty.Type, vals map[string]cty.Value) cty.Value {
+	if len(vals) == 0 {
+		return cty.MapValEmpty(ety)
+	}
+	return cty.MapVal(vals)
+}
+
+func mapValues(vals map[string]cty.Value) []cty.Value {
+	ret := make([]cty.Value, 0, len(vals))
+	for _, v := range vals {
+		ret = append(ret, v)
+	}
+	return ret
+}
+
+// checkElementTypes returns an error if the elements of a collection
+// whose element type is dynamic do not share the same type, as cty
+// would panic when building the collection.
+func checkElementTypes(path AttributePath, vals []cty.Value) error {
+	ety := cty.DynamicPseudoType
+	for _, v := range vals {
+		ty := v.Type()
+		switch {
+		case ty == cty.DynamicPseudoType:
+		case ety == cty.DynamicPseudoType:
+			ety = ty
+		case !ety.Equals(ty):
+			return fmt.Errorf("%s: inconsistent element types %s and %s", pathDisplay(path), ety.FriendlyName(), ty.FriendlyName())
+		}
+	}
+	return nil
+}
+
+// checkObjectKeys returns an error for the first key of obj, in sorted
+// order, for which known returns false.
+func checkObjectKeys(path AttributePath, obj map[string]interface{}, known func(string) bool) error {
+	for _, key := range mergedKeys(obj, nil) {
+		if !known(key) {
+			return fmt.Errorf("%s: unsupported attribute %q", pathDisplay(path), key)
+		}
+	}
+	return nil
+}
+
+func valueTypeError(path AttributePath, want string, v interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %T", pathDisplay(path), want, v)
+}
+
+// pathDisplay returns the path for use in error messages.
+func pathDisplay(path AttributePath) string {
+	if len(path) == 0 {
+		return "value"
+	}
+	return path.String()
+}
@@ -0,0 +1,240 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+func testValueSchema() *Schema {
+	return &Schema{
+		Block: &SchemaBlock{
+			Attributes: map[string]*SchemaAttribute{
+				"id":       {AttributeType: cty.String, Computed: true},
+				"size":     {AttributeType: cty.Number, Optional: true},
+				"enabled":  {AttributeType: cty.Bool, Optional: true},
+				"tags":     {AttributeType: cty.Map(cty.String), Optional: true},
+				"zones":    {AttributeType: cty.Set(cty.String), Optional: true},
+				"password": {AttributeType: cty.String, Optional: true, Sensitive: true},
+				"extra":    {AttributeType: cty.DynamicPseudoType, Optional: true},
+				"endpoints": {
+					AttributeNestedType: &SchemaNestedAttributeType{
+						NestingMode: SchemaNestingModeSet,
+						Attributes: map[string]*SchemaAttribute{
+							"host": {AttributeType: cty.String, Required: true},
+						},
+					},
+					Optional: true,
+				},
+				"rules": {
+					AttributeNestedType: &SchemaNestedAttributeType{
+						NestingMode: SchemaNestingModeList,
+						Attributes: map[string]*SchemaAttribute{
+							"value": {AttributeType: cty.DynamicPseudoType, Optional: true},
+						},
+					},
+					Optional: true,
+				},
+			},
+			NestedBlocks: map[string]*SchemaBlockType{
+				"disk": {
+					NestingMode: SchemaNestingModeList,
+					Block: &SchemaBlock{
+						Attributes: map[string]*SchemaAttribute{
+							"size": {AttributeType: cty.Number, Required: true},
+						},
+					},
+				},
+				"timeouts": {
+					NestingMode: SchemaNestingModeSingle,
+					Block: &SchemaBlock{
+						Attributes: map[string]*SchemaAttribute{
+							"create": {AttributeType: cty.String, Optional: true},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestSchemaValue(t *testing.T) {
+	schema := testValueSchema()
+	values := map[string]interface{}{
+		"id":       "i-1",
+		"size":     json.Number("9007199254740993"),
+		"enabled":  true,
+		"tags":     map[string]interface{}{"env": "prod"},
+		"zones":    []interface{}{"b", "a"},
+		"password": "secret",
+		"extra":    map[string]interface{}{"list": []interface{}{"x", float64(1)}},
+		"endpoints": []interface{}{
+			map[string]interface{}{"host": "a.example.com"},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{"value": "a"},
+			map[string]interface{}{"value": "b"},
+		},
+		"disk": []interface{}{
+			map[string]interface{}{"size": float64(0.1)},
+		},
+		"timeouts": nil,
+	}
+
+	actual, err := schema.Value(values, nil, map[string]interface{}{"password": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("i-1"),
+		"size":     cty.MustParseNumberVal("9007199254740993"),
+		"enabled":  cty.True,
+		"tags":     cty.MapVal(map[string]cty.Value{"env": cty.StringVal("prod")}),
+		"zones":    cty.SetVal([]cty.Value{cty.StringVal("a"), cty.StringVal("b")}),
+		"password": cty.StringVal("secret").Mark(SensitiveMark),
+		"extra": cty.ObjectVal(map[string]cty.Value{
+			"list": cty.TupleVal([]cty.Value{cty.StringVal("x"), cty.NumberIntVal(1)}),
+		}),
+		"endpoints": cty.SetVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"host": cty.StringVal("a.example.com")}),
+		}),
+		"rules": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"value": cty.StringVal("a")}),
+			cty.ObjectVal(map[string]cty.Value{"value": cty.StringVal("b")}),
+		}),
+		"disk": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{"size": cty.MustParseNumberVal("0.1")}),
+		}),
+		"timeouts": cty.NullVal(cty.Object(map[string]cty.Type{"create": cty.String})),
+	})
+
+	if !actual.RawEquals(expected) {
+		t.Fatalf("unexpected value\nexpected: %#v\nactual:   %#v", expected, actual)
+	}
+}
+
+func TestChangeAfterValue(t *testing.T) {
+	schema := testValueSchema()
+	change := &Change{
+		Before: nil,
+		After: map[string]interface{}{
+			"zones": []interface{}{"a", nil},
+			"tags":  map[string]interface{}{},
+		},
+		AfterUnknown: map[string]interface{}{
+			"id":    true,
+			"zones": []interface{}{false, true},
+		},
+		AfterSensitive: map[string]interface{}{
+			"tags": true,
+		},
+	}
+
+	before, err := change.BeforeValue(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !before.IsNull() {
+		t.Fatalf("expected null before value, got %#v", before)
+	}
+
+	after, err := change.AfterValue(schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := after.GetAttr("id"); id.IsKnown() {
+		t.Fatalf("expected unknown id, got %#v", id)
+	}
+	if tags := after.GetAttr("tags"); !tags.RawEquals(cty.MapValEmpty(cty.String).Mark(SensitiveMark)) {
+		t.Fatalf("expected empty sensitive tags, got %#v", tags)
+	}
+	zones := cty.SetVal([]cty.Value{cty.StringVal("a"), cty.UnknownVal(cty.String)})
+	if actual := after.GetAttr("zones"); !actual.RawEquals(zones) {
+		t.Fatalf("expected %#v, got %#v", zones, actual)
+	}
+	if size := after.GetAttr("size"); !size.RawEquals(cty.NullVal(cty.Number)) {
+		t.Fatalf("expected null size, got %#v", size)
+	}
+}
+
+func TestStateResourceValue(t *testing.T) {
+	r := &StateResource{
+		AttributeValues: map[string]interface{}{
+			"id":   "i-1",
+			"disk": []interface{}{},
+		},
+		SensitiveValues: map[string]interface{}{"disk": []interface{}{}},
+	}
+
+	actual, err := r.Value(testValueSchema())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id := actual.GetAttr("id"); !id.RawEquals(cty.StringVal("i-1")) {
+		t.Fatalf("unexpected id %#v", id)
+	}
+	disk := actual.GetAttr("disk")
+	if !disk.Type().IsListType() || disk.LengthInt() != 0 {
+		t.Fatalf("expected empty list of disks, got %#v", disk)
+	}
+}
+
+func TestSchemaValue_errors(t *testing.T) {
+	cases := map[string]struct {
+		values   interface{}
+		expected string
+	}{
+		"unsupported attribute": {
+			values:   map[string]interface{}{"nope": "x"},
+			expected: `value: unsupported attribute "nope"`,
+		},
+		"wrong type": {
+			values:   map[string]interface{}{"size": "big"},
+			expected: "size: expected a number, got string",
+		},
+		"nested wrong type": {
+			values: map[string]interface{}{
+				"disk": []interface{}{map[string]interface{}{"size": true}},
+			},
+			expected: "disk[0].size: expected a number, got bool",
+		},
+		"inconsistent nested attribute types": {
+			values: map[string]interface{}{
+				"rules": []interface{}{
+					map[string]interface{}{"value": "a"},
+					map[string]interface{}{"value": true},
+				},
+			},
+			expected: "rules: inconsistent element types",
+		},
+		"not an object": {
+			values:   []interface{}{},
+			expected: "value: expected an object, got []interface {}",
+		},
+	}
+
+	schema := testValueSchema()
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := schema.Value(tc.values, nil, nil)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Fatalf("expected error %q, got %q", tc.expected, err)
+			}
+		})
+	}
+
+	if _, err := (*Schema)(nil).Value(nil, nil, nil); err == nil {
+		t.Fatal("expected error for nil schema")
+	}
+}
@@ -0,0 +1,329 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+var (
+	// ErrValueNotFound is returned when an object has no value for an
+	// attribute or key of a path.
+	ErrValueNotFound = errors.New("value not found")
+
+	// ErrIndexOutOfRange is returned when an index of a path is out of
+	// the range of a list.
+	ErrIndexOutOfRange = errors.New("index out of range")
+
+	// ErrTypeMismatch is returned when a step of a path cannot be
+	// applied to a value, such as an index applied to an object, or
+	// any step applied to a primitive value.
+	ErrTypeMismatch = errors.New("type mismatch")
+)
+
+// ValuePathError is returned by GetValue, SetValue and DeleteValue
+// when a path cannot be followed. It wraps one of ErrValueNotFound,
+// ErrIndexOutOfRange or ErrTypeMismatch.
+type ValuePathError struct {
+	// The path up to and including the step that failed.
+	Path AttributePath
+
+	// The reason for the failure.
+	Err error
+
+	// A human-readable description of the failure.
+	Detail string
+}
+
+func (e *ValuePathError) Error() string {
+	return fmt.Sprintf("%s: %s", pathDisplay(e.Path), e.Detail)
+}
+
+func (e *ValuePathError) Unwrap() error {
+	return e.Err
+}
+
+// GetValue returns the value at path within a JSON value tree, such as
+// StateResource.AttributeValues, Change.After or Change.AfterSensitive.
+// AttributeStep and KeyStep both select a key of an object, and
+// IndexStep selects an element of a list.
+//
+// In mask trees, true marks the whole subtree beneath it, so paths
+// beneath it return ErrTypeMismatch.
+func GetValue(v interface{}, path AttributePath) (interface{}, error) {
+	for i, step := range path {
+		var err error
+		if v, err = childValue(v, path[:i+1], step); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// SetValue sets the value at path within a JSON value tree, returning
+// the updated tree. Objects and lists are modified in place, so the
+// returned tree only differs from v when path is empty.
+//
+// Setting a missing key of an object adds it, but the parent of the
+// value must exist and lists are never extended: ErrValueNotFound and
+// ErrIndexOutOfRange are returned respectively.
+func SetValue(v interface{}, path AttributePath, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	parent, err := GetValue(v, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	step := path[len(path)-1]
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		if key, ok := step.Index().(string); ok {
+			parent[key] = value
+			return v, nil
+		}
+	case []interface{}:
+		if i, ok := step.(IndexStep); ok {
+			if int(i) < 0 || int(i) >= len(parent) {
+				return nil, indexOutOfRange(path, len(parent))
+			}
+			parent[i] = value
+			return v, nil
+		}
+	}
+	return nil, stepMismatch(path, parent)
+}
+
+// DeleteValue removes the value at path from a JSON value tree,
+// returning the updated tree. Keys are deleted from objects, and
+// elements removed from lists, shifting the following elements.
+// Deleting the empty path returns nil.
+//
+// As for SetValue, objects and lists are modified in place, but lists
+// are shortened: always use the returned tree.
+func DeleteValue(v interface{}, path AttributePath) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil
+	}
+
+	parentPath := path[:len(path)-1]
+	parent, err := GetValue(v, parentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	step := path[len(path)-1]
+	switch parent := parent.(type) {
+	case map[string]interface{}:
+		if key, ok := step.Index().(string); ok {
+			if _, ok := parent[key]; !ok {
+				return nil, valueNotFound(path)
+			}
+			delete(parent, key)
+			return v, nil
+		}
+	case []interface{}:
+		if i, ok := step.(IndexStep); ok {
+			if int(i) < 0 || int(i) >= len(parent) {
+				return nil, indexOutOfRange(path, len(parent))
+			}
+			list := make([]interface{}, 0, len(parent)-1)
+			list = append(list, parent[:i]...)
+			list = append(list, parent[i+1:]...)
+			return SetValue(v, parentPath, list)
+		}
+	}
+	return nil, stepMismatch(path, parent)
+}
+
+// childValue applies the last step of path, which is step, to v.
+func childValue(v interface{}, path AttributePath, step AttributePathStep) (interface{}, error) {
+	switch cur := v.(type) {
+	case map[string]interface{}:
+		key, ok := step.Index().(string)
+		if !ok {
+			break
+		}
+		child, ok := cur[key]
+		if !ok {
+			return nil, valueNotFound(path)
+		}
+		return child, nil
+	case []interface{}:
+		i, ok := step.(IndexStep)
+		if !ok {
+			break
+		}
+		if int(i) < 0 || int(i) >= len(cur) {
+			return nil, indexOutOfRange(path, len(cur))
+		}
+		return cur[i], nil
+	}
+	return nil, stepMismatch(path, v)
+}
+
+func valueNotFound(path AttributePath) error {
+	return &ValuePathError{
+		Path:   path,
+		Err:    ErrValueNotFound,
+		Detail: "no such attribute or key",
+	}
+}
+
+func indexOutOfRange(path AttributePath, length int) error {
+	return &ValuePathError{
+		Path:   path,
+		Err:    ErrIndexOutOfRange,
+		Detail: fmt.Sprintf("index out of range for list of length %d", length),
+	}
+}
+
+func stepMismatch(path AttributePath, v interface{}) error {
+	want := "an object"
+	if _, ok := path[len(path)-1].(IndexStep); ok {
+		want = "a list"
+	}
+	return &ValuePathError{
+		Path:   path,
+		Err:    ErrTypeMismatch,
+		Detail: fmt.Sprintf("expected %s, got %s", want, jsonTypeName(v)),
+	}
+}
+
+// valueAtPath returns the value found at path within the JSON value
+// tree v, and whether it exists.
+func valueAtPath(v interface{}, path AttributePath) (interface{}, bool) {
+	v, err := GetValue(v, path)
+	return v, err == nil
+}
+
+// maskAtPath returns true if the mask tree, such as
+// Change.AfterSensitive or Change.AfterUnknown, marks the value at
+// path, either directly or through one of its parents.
+func maskAtPath(mask interface{}, path AttributePath) bool {
+	for _, step := range path {
+		if b, ok := mask.(bool); ok {
+			return b
+		}
+		var ok bool
+		if mask, ok = valueAtPath(mask, AttributePath{step}); !ok {
+			return false
+		}
+	}
+	b, ok := mask.(bool)
+	return ok && b
+}
+
+// valueDiffFunc is called by walkValueDiff for each differing value.
+// A value missing from either side is reported as nil.
+type valueDiffFunc func(path AttributePath, before, after interface{})
+
+// walkValueDiff walks two JSON value trees of type ty in lockstep,
+// calling fn for every path at which they differ. Objects and lists
+// are descended into, so only the deepest differing values are
+// reported. Values of different kinds are reported at the path where
+// the kinds diverge. Sets are compared with walkSetDiff.
+//
+// A null value compared to a non-empty object or list is treated as an
+// empty one, so that each of the values it contains is reported.
+func walkValueDiff(path AttributePath, ty cty.Type, before, after interface{}, fn valueDiffFunc) {
+	if before == nil {
+		before = emptyContainerLike(after)
+	}
+	if after == nil {
+		after = emptyContainerLike(before)
+	}
+
+	switch b := before.(type) {
+	case map[string]interface{}:
+		a, ok := after.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range mergedKeys(b, a) {
+			step := AttributeStep(key)
+			walkValueDiff(path.child(step), childType(ty, step), b[key], a[key], fn)
+		}
+		return
+	case []interface{}:
+		a, ok := after.([]interface{})
+		if !ok {
+			break
+		}
+		if ty.IsSetType() {
+			walkSetDiff(path, ty.ElementType(), b, a, fn)
+			return
+		}
+		for i := 0; i < len(b) || i < len(a); i++ {
+			var bv, av interface{}
+			if i < len(b) {
+				bv = b[i]
+			}
+			if i < len(a) {
+				av = a[i]
+			}
+			step := IndexStep(i)
+			walkValueDiff(path.child(step), childType(ty, step), bv, av, fn)
+		}
+		return
+	}
+
+	if !valuesEqual(ty, before, after) {
+		fn(path, before, after)
+	}
+}
+
+// walkSetDiff calls fn for the elements of two sets that have no equal
+// counterpart on the other side, regardless of order. As set elements
+// cannot be paired by position, they are reported whole, at their index
+// on their side: a removed element and an added element sharing an
+// index are reported separately, the removed one first.
+func walkSetDiff(path AttributePath, ety cty.Type, before, after []interface{}, fn valueDiffFunc) {
+	matchedBefore, matchedAfter := matchSetElements(ety, before, after)
+	for i := 0; i < len(before) || i < len(after); i++ {
+		if i < len(before) && !matchedBefore[i] && before[i] != nil {
+			fn(path.child(IndexStep(i)), before[i], nil)
+		}
+		if i < len(after) && !matchedAfter[i] && after[i] != nil {
+			fn(path.child(IndexStep(i)), nil, after[i])
+		}
+	}
+}
+
+// emptyContainerLike returns an empty object or list if v is a
+// non-empty object or list respectively, and nil otherwise.
+func emptyContainerLike(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if len(v) > 0 {
+			return map[string]interface{}{}
+		}
+	case []interface{}:
+		if len(v) > 0 {
+			return []interface{}{}
+		}
+	}
+	return nil
+}
+
+// mergedKeys returns the sorted union of the keys of a and b.
+func mergedKeys(a, b map[string]interface{}) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
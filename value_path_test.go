@@ -0,0 +1,168 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testValueTree() map[string]interface{} {
+	return map[string]interface{}{
+		"name": "web",
+		"tags": map[string]interface{}{"env": "prod"},
+		"disk": []interface{}{
+			map[string]interface{}{"size": float64(10)},
+			map[string]interface{}{"size": float64(20)},
+		},
+	}
+}
+
+func TestGetValue(t *testing.T) {
+	v := testValueTree()
+
+	cases := []struct {
+		path     string
+		expected interface{}
+	}{
+		{"", v},
+		{"name", "web"},
+		{`tags["env"]`, "prod"},
+		{"tags.env", "prod"},
+		{"disk[1].size", float64(20)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := ParseAttributePath(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, err := GetValue(v, path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(tc.expected, actual); diff != "" {
+				t.Fatalf("unexpected value (-expected +actual):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGetValue_errors(t *testing.T) {
+	cases := []struct {
+		path     string
+		expected error
+		message  string
+	}{
+		{"missing", ErrValueNotFound, "missing: no such attribute or key"},
+		{"disk[2]", ErrIndexOutOfRange, "disk[2]: index out of range for list of length 2"},
+		{"disk.size", ErrTypeMismatch, "disk.size: expected an object, got array"},
+		{"tags[0]", ErrTypeMismatch, "tags[0]: expected a list, got object"},
+		{"name.first", ErrTypeMismatch, "name.first: expected an object, got string"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			path, err := ParseAttributePath(tc.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = GetValue(testValueTree(), path)
+			if !errors.Is(err, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, err)
+			}
+			var pathErr *ValuePathError
+			if !errors.As(err, &pathErr) || !pathErr.Path.Equal(path) {
+				t.Fatalf("expected ValuePathError for %s, got %#v", path, err)
+			}
+			if err.Error() != tc.message {
+				t.Fatalf("expected message %q, got %q", tc.message, err)
+			}
+		})
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	v := testValueTree()
+
+	set := func(path string, value interface{}) {
+		t.Helper()
+		p, err := ParseAttributePath(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := SetValue(v, p, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	set("name", "api")
+	set(`tags["team"]`, "core")
+	set("disk[0].size", float64(15))
+
+	expected := map[string]interface{}{
+		"name": "api",
+		"tags": map[string]interface{}{"env": "prod", "team": "core"},
+		"disk": []interface{}{
+			map[string]interface{}{"size": float64(15)},
+			map[string]interface{}{"size": float64(20)},
+		},
+	}
+	if diff := cmp.Diff(expected, v); diff != "" {
+		t.Fatalf("unexpected tree (-expected +actual):\n%s", diff)
+	}
+
+	root, err := SetValue(v, nil, "replaced")
+	if err != nil || root != "replaced" {
+		t.Fatalf("expected root to be replaced, got %#v, %v", root, err)
+	}
+
+	if _, err := SetValue(v, AttributePath{AttributeStep("disk"), IndexStep(2)}, nil); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := SetValue(v, AttributePath{AttributeStep("missing"), AttributeStep("x")}, nil); !errors.Is(err, ErrValueNotFound) {
+		t.Fatalf("expected ErrValueNotFound, got %v", err)
+	}
+	if _, err := SetValue(v, AttributePath{AttributeStep("disk"), AttributeStep("x")}, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestDeleteValue(t *testing.T) {
+	v := interface{}(testValueTree())
+
+	var err error
+	for _, path := range []AttributePath{
+		{AttributeStep("tags"), KeyStep("env")},
+		{AttributeStep("disk"), IndexStep(0)},
+	} {
+		if v, err = DeleteValue(v, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]interface{}{
+		"name": "web",
+		"tags": map[string]interface{}{},
+		"disk": []interface{}{
+			map[string]interface{}{"size": float64(20)},
+		},
+	}
+	if diff := cmp.Diff(expected, v); diff != "" {
+		t.Fatalf("unexpected tree (-expected +actual):\n%s", diff)
+	}
+
+	if _, err := DeleteValue(v, AttributePath{AttributeStep("tags"), KeyStep("env")}); !errors.Is(err, ErrValueNotFound) {
+		t.Fatalf("expected ErrValueNotFound, got %v", err)
+	}
+	if _, err := DeleteValue(v, AttributePath{AttributeStep("disk"), IndexStep(1)}); !errors.Is(err, ErrIndexOutOfRange) {
+		t.Fatalf("expected ErrIndexOutOfRange, got %v", err)
+	}
+	if _, err := DeleteValue(v, AttributePath{AttributeStep("name"), IndexStep(0)}); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+	if root, err := DeleteValue(v, nil); err != nil || root != nil {
+		t.Fatalf("expected nil root, got %#v, %v", root, err)
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"sort"
+)
+
+// SkipAll can be returned by a StateWalkFunc or ConfigWalkFunc to stop
+// walking the remaining resources. The walk then returns a nil error.
+var SkipAll = errors.New("skip all remaining resources")
+
+// StateWalkFunc is the type of the function called by StateModule.Walk
+// for each resource. module is the module that directly contains r.
+//
+// If the function returns an error, the walk stops and the error is
+// returned by Walk, except for SkipAll which stops the walk without an
+// error.
+type StateWalkFunc func(module *StateModule, r *StateResource) error
+
+// Walk calls fn for each resource in the module and all of its child
+// modules, depth-first, in the order they appear in Resources and
+// ChildModules.
+func (m *StateModule) Walk(fn StateWalkFunc) error {
+	err := m.walk(fn)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (m *StateModule) walk(fn StateWalkFunc) error {
+	if m == nil {
+		return nil
+	}
+
+	for _, r := range m.Resources {
+		if r == nil {
+			continue
+		}
+		if err := fn(m, r); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range m.ChildModules {
+		if err := child.walk(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ConfigWalkFunc is the type of the function called by
+// ConfigModule.Walk for each resource. path is the path of the module
+// declaring r, relative to the module Walk was called on, and calls is
+// the chain of module calls leading to it.
+//
+// If the function returns an error, the walk stops and the error is
+// returned by Walk, except for SkipAll which stops the walk without an
+// error.
+type ConfigWalkFunc func(path ModulePath, calls []*ModuleCall, r *ConfigResource) error
+
+// Walk calls fn for each resource in the module and all of the modules
+// it calls, depth-first. Module calls are visited in lexical order of
+// their names.
+func (m *ConfigModule) Walk(fn ConfigWalkFunc) error {
+	err := m.walk(nil, nil, fn)
+	if err == SkipAll {
+		return nil
+	}
+	return err
+}
+
+func (m *ConfigModule) walk(path ModulePath, calls []*ModuleCall, fn ConfigWalkFunc) error {
+	if m == nil {
+		return nil
+	}
+
+	for _, r := range m.Resources {
+		if r == nil {
+			continue
+		}
+		if err := fn(path, calls, r); err != nil {
+			return err
+		}
+	}
+
+	names := make([]string, 0, len(m.ModuleCalls))
+	for name := range m.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		call := m.ModuleCalls[name]
+		if call == nil {
+			continue
+		}
+		// Use full slice expressions so that sibling modules never
+		// share the backing arrays passed to fn.
+		childPath := append(path[:len(path):len(path)], name)
+		childCalls := append(calls[:len(calls):len(calls)], call)
+		if err := call.Module.walk(childPath, childCalls, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build go1.23
+
+package tfjson
+
+import "iter"
+
+// AllResources returns an iterator over the resources of the module
+// and all of its child modules, in the same order as Walk. Each
+// resource is yielded along with the module that directly contains it.
+func (m *StateModule) AllResources() iter.Seq2[*StateModule, *StateResource] {
+	return func(yield func(*StateModule, *StateResource) bool) {
+		_ = m.Walk(func(module *StateModule, r *StateResource) error {
+			if !yield(module, r) {
+				return SkipAll
+			}
+			return nil
+		})
+	}
+}
+
+// AllResources returns an iterator over the resources of the module
+// and all of the modules it calls, in the same order as Walk. Each
+// resource is yielded along with the path of the module declaring it.
+func (m *ConfigModule) AllResources() iter.Seq2[ModulePath, *ConfigResource] {
+	return func(yield func(ModulePath, *ConfigResource) bool) {
+		_ = m.Walk(func(path ModulePath, _ []*ModuleCall, r *ConfigResource) error {
+			if !yield(path, r) {
+				return SkipAll
+			}
+			return nil
+		})
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+//go:build go1.23
+
+package tfjson
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestStateModuleAllResources(t *testing.T) {
+	var actual []string
+	for _, r := range testWalkStateModule().AllResources() {
+		actual = append(actual, r.Address)
+		if len(actual) == 3 {
+			break
+		}
+	}
+
+	expected := []string{
+		"null_resource.a",
+		"module.foo.null_resource.b",
+		"module.foo.module.bar[0].null_resource.c",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected resources: %s", diff)
+	}
+}
+
+func TestConfigModuleAllResources(t *testing.T) {
+	var actual []string
+	for path, r := range testWalkConfigModule().AllResources() {
+		actual = append(actual, path.String()+" "+r.Address)
+	}
+
+	expected := []string{
+		" null_resource.a",
+		"module.baz null_resource.d",
+		"module.foo null_resource.b",
+		"module.foo.module.bar null_resource.c",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected resources: %s", diff)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package tfjson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testWalkStateModule() *StateModule {
+	return &StateModule{
+		Resources: []*StateResource{{Address: "null_resource.a"}},
+		ChildModules: []*StateModule{
+			{
+				Address:   "module.foo",
+				Resources: []*StateResource{{Address: "module.foo.null_resource.b"}},
+				ChildModules: []*StateModule{
+					{
+						Address:   "module.foo.module.bar[0]",
+						Resources: []*StateResource{{Address: "module.foo.module.bar[0].null_resource.c"}},
+					},
+				},
+			},
+			{
+				Address:   "module.baz",
+				Resources: []*StateResource{{Address: "module.baz.null_resource.d"}},
+			},
+		},
+	}
+}
+
+func testWalkConfigModule() *ConfigModule {
+	return &ConfigModule{
+		Resources: []*ConfigResource{{Address: "null_resource.a"}},
+		ModuleCalls: map[string]*ModuleCall{
+			"foo": {
+				Source: "./foo",
+				Module: &ConfigModule{
+					Resources: []*ConfigResource{{Address: "null_resource.b"}},
+					ModuleCalls: map[string]*ModuleCall{
+						"bar": {
+							Source: "./bar",
+							Module: &ConfigModule{
+								Resources: []*ConfigResource{{Address: "null_resource.c"}},
+							},
+						},
+					},
+				},
+			},
+			"baz": {
+				Source: "./baz",
+				Module: &ConfigModule{
+					Resources: []*ConfigResource{{Address: "null_resource.d"}},
+				},
+			},
+		},
+	}
+}
+
+func TestStateModuleWalk(t *testing.T) {
+	var actual []string
+	err := testWalkStateModule().Walk(func(m *StateModule, r *StateResource) error {
+		actual = append(actual, m.Address+" "+r.Address)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		" null_resource.a",
+		"module.foo module.foo.null_resource.b",
+		"module.foo.module.bar[0] module.foo.module.bar[0].null_resource.c",
+		"module.baz module.baz.null_resource.d",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected walk: %s", diff)
+	}
+}
+
+func TestStateModuleWalk_stop(t *testing.T) {
+	errStop := errors.New("stop")
+
+	cases := []struct {
+		name     string
+		ret      error
+		expected error
+	}{
+		{"skip all", SkipAll, nil},
+		{"error", errStop, errStop},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var visited int
+			err := testWalkStateModule().Walk(func(_ *StateModule, r *StateResource) error {
+				visited++
+				if r.Address == "module.foo.null_resource.b" {
+					return tc.ret
+				}
+				return nil
+			})
+			if err != tc.expected {
+				t.Fatalf("expected error %v, got %v", tc.expected, err)
+			}
+			if visited != 2 {
+				t.Fatalf("expected walk to stop after 2 resources, visited %d", visited)
+			}
+		})
+	}
+}
+
+func TestConfigModuleWalk(t *testing.T) {
+	var actual []string
+	err := testWalkConfigModule().Walk(func(path ModulePath, calls []*ModuleCall, r *ConfigResource) error {
+		if len(calls) != len(path) {
+			t.Fatalf("expected %d module calls, got %d", len(path), len(calls))
+		}
+		var sources string
+		for _, call := range calls {
+			sources += call.Source
+		}
+		actual = append(actual, path.String()+" "+sources+" "+r.Address)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"  null_resource.a",
+		"module.baz ./baz null_resource.d",
+		"module.foo ./foo null_resource.b",
+		"module.foo.module.bar ./foo./bar null_resource.c",
+	}
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Fatalf("unexpected walk: %s", diff)
+	}
+
+	var visited int
+	err = testWalkConfigModule().Walk(func(ModulePath, []*ModuleCall, *ConfigResource) error {
+		visited++
+		return SkipAll
+	})
+	if err != nil || visited != 1 {
+		t.Fatalf("expected walk to stop after 1 resource, visited %d (err %v)", visited, err)
+	}
+}